@@ -0,0 +1,155 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/config"
+
+	"github.com/spf13/cobra"
+)
+
+// Shell completion for the flags and arguments that take a course, group,
+// date or time. Anything after a tab in a suggestion is its description,
+// which cobra shows next to it in shells that support them (zsh and fish).
+
+// Tee times offered for --time, every half hour from first light to twilight
+const (
+	firstTeeTime = 6 * 60
+	lastTeeTime  = 17 * 60
+)
+
+// Helper function to load the courses for completing. Nothing is printed
+// as anything written to stdout would end up in the suggestions.
+func completionCourses() map[string]CourseConfig {
+	// PersistentPreRunE does run for __complete, but before the flags on the
+	// line being completed are parsed, so --config has to be picked up here
+	applyConfigFlag()
+	if demoMode {
+		return demoCourseConfigs()
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil
+	}
+	return courseConfigs(cfg)
+}
+
+// completeCourses suggests the courses whose name or alias contains what's
+// been typed so far, described by their website type
+func completeCourses(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return courseCompletions(completionCourses(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCourseArg completes the course name taken by config subcommands
+func completeCourseArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeCourses(cmd, args, toComplete)
+}
+
+// completeGroups suggests the tags used in the config
+func completeGroups(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	courses := completionCourses()
+
+	var completions []string
+	for _, tag := range courseTags(courses) {
+		if !strings.HasPrefix(strings.ToLower(tag), strings.ToLower(toComplete)) {
+			continue
+		}
+		count := 0
+		for _, cfg := range courses {
+			if cfg.hasTag(tag) {
+				count++
+			}
+		}
+		noun := "courses"
+		if count == 1 {
+			noun = "course"
+		}
+		completions = append(completions, fmt.Sprintf("%s\t%d %s", tag, count, noun))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeDates suggests the dates that can be searched, starting today
+func completeDates(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return dateCompletions(time.Now(), toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeTimes suggests common tee times
+func completeTimes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return timeCompletions(toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// Helper function to list the matching courses alphabetically with their
+// descriptions
+func courseCompletions(courses map[string]CourseConfig, toComplete string) []string {
+	typed := strings.ToLower(strings.TrimSpace(toComplete))
+
+	var names []string
+	for name, cfg := range courses {
+		for _, candidate := range append([]string{name}, cfg.Aliases...) {
+			if strings.Contains(strings.ToLower(candidate), typed) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+
+	completions := make([]string, len(names))
+	for i, name := range names {
+		desc := courses[name].WebsiteType
+		if courses[name].Blacklisted {
+			desc += ", blacklisted"
+		}
+		completions[i] = name + "\t" + desc
+	}
+	return completions
+}
+
+// Helper function to list the next maxSearchDays dates in the -d format,
+// described by the day of the week
+func dateCompletions(today time.Time, toComplete string) []string {
+	var completions []string
+	for i := 0; i < maxSearchDays; i++ {
+		day := today.AddDate(0, 0, i)
+		date := day.Format("02-01-2006")
+		if !strings.HasPrefix(date, toComplete) {
+			continue
+		}
+
+		desc := day.Format("Monday")
+		switch i {
+		case 0:
+			desc += " (today)"
+		case 1:
+			desc += " (tomorrow)"
+		}
+		completions = append(completions, date+"\t"+desc)
+	}
+	return completions
+}
+
+// Helper function to list the half hours between the first and last tee
+// times in the -t format, described in 12-hour time
+func timeCompletions(toComplete string) []string {
+	var completions []string
+	for mins := firstTeeTime; mins <= lastTeeTime; mins += 30 {
+		clock := fmt.Sprintf("%02d:%02d", mins/60, mins%60)
+		if strings.HasPrefix(clock, toComplete) {
+			completions = append(completions, clock+"\t"+formatMinutesAs12Hour(mins))
+		}
+	}
+	return completions
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/config"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCourseCompletions(t *testing.T) {
+	courses := map[string]CourseConfig{
+		"The Springs Golf Course":  {WebsiteType: "quick18"},
+		"Hamersley Golf Course":    {WebsiteType: "quick18", Aliases: []string{"hamo"}},
+		"Collier Park Golf Course": {WebsiteType: "miclub", Blacklisted: true},
+	}
+
+	assert.Equal(t, []string{
+		"Collier Park Golf Course\tmiclub, blacklisted",
+		"Hamersley Golf Course\tquick18",
+		"The Springs Golf Course\tquick18",
+	}, courseCompletions(courses, ""))
+
+	assert.Equal(t, []string{"The Springs Golf Course\tquick18"}, courseCompletions(courses, "SPRINGS"), "matches inside the name")
+	assert.Equal(t, []string{"Hamersley Golf Course\tquick18"}, courseCompletions(courses, "ham"), "matches an alias")
+	assert.Empty(t, courseCompletions(courses, "nowhere"))
+}
+
+func TestCompleteFromConfig(t *testing.T) {
+	cfgPath, restore := withTempConfigPath(t, "config.yaml")
+	defer restore()
+	require.NoError(t, config.Save(cfgPath, &config.Config{Courses: []config.Course{
+		{Name: "Hamersley Golf Course", URL: "https://hamersley.quick18.com/teetimes/searchmatrix", WebsiteType: "quick18", Tags: []string{"north"}},
+		{Name: "Wembley Golf Course", URL: "https://wembley.miclub.com.au", WebsiteType: "miclub", Tags: []string{"north"}},
+		{Name: "Collier Park Golf Course", URL: "https://bookings.collierparkgolf.com.au", WebsiteType: "miclub", Tags: []string{"South"}},
+	}}))
+
+	got, directive := completeCourseArg(configEditCmd, nil, "wem")
+	assert.Equal(t, []string{"Wembley Golf Course\tmiclub"}, got)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	got, _ = completeCourseArg(configEditCmd, []string{"Wembley Golf Course"}, "")
+	assert.Empty(t, got, "config edit only takes one course")
+
+	got, _ = completeGroups(rootCmd, nil, "")
+	assert.Equal(t, []string{"north\t2 courses", "South\t1 course"}, got)
+
+	configPath = cfgPath + ".missing"
+	got, _ = completeCourses(rootCmd, nil, "")
+	assert.Empty(t, got, "a missing config has nothing to suggest")
+}
+
+func TestDateCompletions(t *testing.T) {
+	friday := time.Date(2025, 2, 21, 15, 0, 0, 0, time.Local)
+
+	got := dateCompletions(friday, "")
+	require.Len(t, got, maxSearchDays)
+	assert.Equal(t, "21-02-2025\tFriday (today)", got[0])
+	assert.Equal(t, "22-02-2025\tSaturday (tomorrow)", got[1])
+	assert.Equal(t, "23-02-2025\tSunday", got[2])
+	assert.Equal(t, "06-03-2025\tThursday", got[maxSearchDays-1])
+
+	assert.Equal(t, []string{"01-03-2025\tSaturday", "02-03-2025\tSunday"}, dateCompletions(friday, "0")[:2], "only dates starting with what's typed")
+	assert.Equal(t, []string{"28-02-2025\tFriday"}, dateCompletions(friday, "28"))
+}
+
+func TestTimeCompletions(t *testing.T) {
+	got := timeCompletions("")
+	assert.Equal(t, "06:00\t06:00 AM", got[0])
+	assert.Equal(t, "17:00\t05:00 PM", got[len(got)-1])
+
+	assert.Equal(t, []string{"07:00\t07:00 AM", "07:30\t07:30 AM"}, timeCompletions("07"))
+	assert.Empty(t, timeCompletions("23"))
+}
@@ -12,14 +12,17 @@ Collects information such as:
 package cmd
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/config"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/provider"
+
 	"github.com/charmbracelet/bubbles/cursor"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -27,45 +30,79 @@ import (
 	"github.com/spf13/cobra"
 )
 
-type CourseInfo struct {
-	Name        string
-	URL         string
-	WebsiteType string
-	Blacklisted bool
-}
-
 type configModel struct {
 	focusIndex int
 	inputs     []textinput.Model
 	cursorMode cursor.Mode
-	courses    []CourseInfo
-	current    CourseInfo
+	existing   []config.Course // already in the config, for spotting duplicates
+	courses    []config.Course
+	current    config.Course
 	done       bool
 	err        error
 	success    string
 }
 
 type blacklistItem struct {
-	course CourseInfo
+	course config.Course
 	index  int
 }
 
 type blacklistModel struct {
 	list    list.Model
-	courses []CourseInfo
+	courses []config.Course
 }
 
 type deleteItem struct {
-	course   CourseInfo
+	course   config.Course
 	index    int
 	selected bool
 }
 
 var (
-	configPath = filepath.Join(os.Getenv("HOME"), ".config", "TeeTimeFinder", "config.txt")
+	configPath = defaultConfigPath()
+	configFile string // --config, replaces configPath when it's given
 	overwrite  bool
+	verifyURLs bool
 )
 
+// configEnv can point TeeTimeFinder at a different config file
+const configEnv = "TEETIMEFINDER_CONFIG"
+
+// defaultConfigPath works out where the config lives when --config isn't
+// given: $TEETIMEFINDER_CONFIG, then the standard place.
+func defaultConfigPath() string {
+	if path := os.Getenv(configEnv); path != "" {
+		return path
+	}
+	return standardConfigPath()
+}
+
+// standardConfigPath is $XDG_CONFIG_HOME/TeeTimeFinder/config.yaml, or
+// ~/.config/TeeTimeFinder/config.yaml. It doesn't use os.UserConfigDir as
+// that's somewhere else on macOS and existing configs would go missing.
+func standardConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" || !filepath.IsAbs(dir) {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "TeeTimeFinder", "config.yaml")
+}
+
+// legacyConfigPath is where versions before the YAML config kept the
+// courses, whatever XDG_CONFIG_HOME is set to
+func legacyConfigPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "TeeTimeFinder", config.LegacyFile)
+}
+
+// Helper function to use the --config file, if one was given
+func applyConfigFlag() {
+	if configFile != "" {
+		configPath = configFile
+	}
+}
+
 // Checks if the config file exists
 func ConfigExists() bool {
 	_, err := os.Stat(configPath)
@@ -86,92 +123,85 @@ func CreateDir() bool {
 	return true
 }
 
-// Loads the existing courses from the config file into a map
-func loadExistingCourses() map[string]CourseInfo {
-	courses := make(map[string]CourseInfo)
-
-	if !ConfigExists() {
-		return courses
+// Loads the config file, a missing file is the same as an empty one
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return &config.Config{}, nil
 	}
+	return cfg, err
+}
 
-	file, err := os.Open(configPath)
-	if err != nil {
-		fmt.Printf("Failed to open config file: %s\n", err)
-		return courses
+// Helper function to normalise a URL input and fill an empty website type
+// input in from it. A type that's been typed is left for checkWebsiteType.
+func detectFromURL(urlInput, typeInput *textinput.Model) {
+	p, courseURL, ok := provider.Normalise(urlInput.Value())
+	if !ok {
+		return
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue // Skip empty lines and comments
-		}
-		parts := strings.SplitN(line, ",", 4)
-		if len(parts) < 3 {
-			continue
-		}
-
-		courseName := strings.TrimSpace(parts[0])
-		courseURL := strings.TrimSpace(parts[1])
-		websiteType := strings.TrimSpace(parts[2])
-
-		blacklisted := false
-		if len(parts) == 4 {
-			bl := strings.TrimSpace(parts[3])
-			blacklisted = strings.EqualFold(bl, "true")
-		}
-
-		courses[courseURL] = CourseInfo{
-			Name:        courseName,
-			URL:         courseURL,
-			WebsiteType: websiteType,
-			Blacklisted: blacklisted,
-		}
+	urlInput.SetValue(courseURL)
+	if strings.TrimSpace(typeInput.Value()) == "" {
+		typeInput.SetValue(p.Name())
 	}
-	return courses
 }
 
-// Appends new courses to the config file
-func appendCoursesToFile(courses []CourseInfo) error {
-	file, err := os.OpenFile(configPath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return err
+// Helper function to check a course's website type is one we have a scraper
+// for and, when the URL is one we recognise, the one that reads it
+func checkWebsiteType(c config.Course) error {
+	if _, ok := provider.Lookup(c.WebsiteType); !ok {
+		return fmt.Errorf("Invalid website type, expected one of: %s", strings.Join(provider.Names(), ", "))
 	}
-	defer file.Close()
+	if p, ok := provider.Detect(c.URL); ok && !strings.EqualFold(p.Name(), c.WebsiteType) {
+		return fmt.Errorf("The URL is a %s booking page, not %s", p.Name(), c.WebsiteType)
+	}
+	return nil
+}
 
-	for _, course := range courses {
-		line := fmt.Sprintf("%s,%s,%s,%t\n", course.Name, course.URL, course.WebsiteType, course.Blacklisted)
-		_, err := file.WriteString(line)
-		if err != nil {
-			return err
+// Helper function to check a course's name and aliases aren't already used
+// by one of the others
+func checkNameFree(others []config.Course, c config.Course) error {
+	for _, o := range others {
+		for _, name := range append([]string{c.Name}, c.Aliases...) {
+			if strings.EqualFold(o.Name, name) {
+				return fmt.Errorf("There's already a course called %s", o.Name)
+			}
+			for _, alias := range o.Aliases {
+				if strings.EqualFold(alias, name) {
+					return fmt.Errorf("%s already has the alias %s", o.Name, alias)
+				}
+			}
 		}
 	}
 	return nil
 }
 
-// Overwrites the entire config file
-func overwriteCoursesToFile(courses []CourseInfo) error {
-	file, err := os.OpenFile(configPath, os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0644)
+// Helper function to keep only the courses whose pages can be read, using
+// the same checks as config validate
+func verifyNewCourses(ctx context.Context, courses []config.Course) []config.Course {
+	f, err := newFetcher()
 	if err != nil {
-		return err
+		fmt.Printf("Failed to check courses: %v\n", err)
+		return nil
 	}
-	defer file.Close()
 
-	for _, course := range courses {
-		line := fmt.Sprintf("%s,%s,%s,%t\n", course.Name, course.URL, course.WebsiteType, course.Blacklisted)
-		_, err := file.WriteString(line)
-		if err != nil {
-			return err
+	var ok []config.Course
+	for _, c := range courses {
+		fmt.Printf("Checking %s... ", c.Name)
+		if check := checkCourse(ctx, f, c); check.status != statusOK {
+			fmt.Printf("failed, not saved: %s, %s\n", check.status, check.detail)
+			continue
 		}
+		fmt.Println("OK")
+		ok = append(ok, c)
 	}
-	return nil
+	return ok
 }
 
 // bubbletea logic
-func initialConfigModel() configModel {
+func initialConfigModel(existing []config.Course) configModel {
 	m := configModel{
-		inputs: make([]textinput.Model, 3),
+		inputs:   make([]textinput.Model, 3),
+		existing: existing,
 	}
 	var t textinput.Model
 	for i := range m.inputs {
@@ -188,7 +218,7 @@ func initialConfigModel() configModel {
 		case 1:
 			t.Placeholder = "Course URL"
 		case 2:
-			t.Placeholder = "Website Type (MiClub or Quick18)"
+			t.Placeholder = "Website Type (MiClub or Quick18, filled in from the URL)"
 		}
 		m.inputs[i] = t
 	}
@@ -208,6 +238,7 @@ func (m configModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		s := msg.String()
+		leaving := m.focusIndex
 
 		switch s {
 		case "ctrl+c", "esc":
@@ -228,18 +259,25 @@ func (m configModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "enter":
 			if m.focusIndex == 2 {
-				val := strings.ToLower(m.inputs[2].Value())
-				if val != "miclub" && val != "quick18" {
-					m.err = fmt.Errorf("Invalid website type")
+				m.detectFromURL()
+				m.current.Name = strings.TrimSpace(m.inputs[0].Value())
+				m.current.URL = strings.TrimSpace(m.inputs[1].Value())
+				m.current.WebsiteType = strings.ToLower(strings.TrimSpace(m.inputs[2].Value()))
+				err := checkWebsiteType(m.current)
+				if err == nil {
+					err = m.current.Validate()
+				}
+				if err == nil {
+					err = checkNameFree(append(append([]config.Course(nil), m.existing...), m.courses...), m.current)
+				}
+				if err != nil {
+					m.err = err
 					m.success = ""
 					return m, nil
 				}
-				m.success = fmt.Sprintf("[SUCCESS] Added %s", m.inputs[0].Value())
-				m.current.Name = m.inputs[0].Value()
-				m.current.URL = m.inputs[1].Value()
-				m.current.WebsiteType = val
+				m.success = fmt.Sprintf("[SUCCESS] Added %s", m.current.Name)
 				m.courses = append(m.courses, m.current)
-				m.current = CourseInfo{}
+				m.current = config.Course{}
 				for i := range m.inputs {
 					m.inputs[i].SetValue("")
 				}
@@ -249,6 +287,11 @@ func (m configModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.focusIndex = (m.focusIndex + 1) % len(m.inputs)
 			}
 		}
+
+		// Once the URL is in, tidy it and fill in the website type
+		if leaving == 1 && m.focusIndex != 1 {
+			m.detectFromURL()
+		}
 	}
 
 	// Apply correct focus/blur and styles
@@ -268,6 +311,12 @@ func (m configModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(append(cmds, cmd)...)
 }
 
+// Helper function to normalise the URL and prefill the website type from
+// it, leaving a type that's already been typed alone
+func (m *configModel) detectFromURL() {
+	detectFromURL(&m.inputs[1], &m.inputs[2])
+}
+
 func (m configModel) View() string {
 	var b strings.Builder
 	b.WriteString("Enter golf course info:\n\n")
@@ -329,7 +378,7 @@ func (d blacklistDelegate) Render(w io.Writer, m list.Model, index int, listItem
 	fmt.Fprintf(w, "%s\n%s", title, desc)
 }
 
-func initialBlacklistModel(courses []CourseInfo) blacklistModel {
+func initialBlacklistModel(courses []config.Course) blacklistModel {
 	items := make([]list.Item, len(courses))
 	for i, c := range courses {
 		items[i] = blacklistItem{course: c, index: i}
@@ -425,11 +474,11 @@ func (d deleteDelegate) Render(w io.Writer, m list.Model, index int, listItem li
 // model that wraps the list and keeps track of chosen deletions
 type deleteModel struct {
 	list     list.Model
-	courses  []CourseInfo     // original slice, index-aligned with list items
+	courses  []config.Course  // original slice, index-aligned with list items
 	selected map[int]struct{} // indices picked for removal
 }
 
-func initialDeleteModel(courses []CourseInfo) deleteModel {
+func initialDeleteModel(courses []config.Course) deleteModel {
 	items := make([]list.Item, len(courses))
 	for i, c := range courses {
 		items[i] = deleteItem{course: c, index: i}
@@ -483,7 +532,18 @@ var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Configure golf courses for TeeTimeFinder",
 	Run: func(cmd *cobra.Command, args []string) {
-		p := tea.NewProgram(initialConfigModel())
+		// Either add to the existing courses or start again based on the -o flag
+		cfg := &config.Config{}
+		if !overwrite {
+			var err error
+			cfg, err = loadConfig()
+			if err != nil {
+				fmt.Printf("Failed to read config file, fix it or use -o to start again:\n%v\n", err)
+				return
+			}
+		}
+
+		p := tea.NewProgram(initialConfigModel(cfg.Courses))
 		m, err := p.Run()
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
@@ -491,24 +551,21 @@ var configCmd = &cobra.Command{
 		}
 		model := m.(configModel)
 
+		// Check the pages can be read before saving them
+		if verifyURLs {
+			model.courses = verifyNewCourses(cmd.Context(), model.courses)
+		}
+
 		// No courses to add
 		if len(model.courses) == 0 {
 			fmt.Println("No courses were added.")
 			return
 		}
 
-		// Ensure the directory exists
-		if !CreateDir() {
-			return
-		}
+		// The form turns away names already in use, so nothing is replaced
+		cfg.Courses = append(cfg.Courses, model.courses...)
 
-		// Either append or overwrite the file based on the -o flag
-		if overwrite {
-			err = overwriteCoursesToFile(model.courses)
-		} else {
-			err = appendCoursesToFile(model.courses)
-		}
-		if err != nil {
+		if err := config.Save(configPath, cfg); err != nil {
 			fmt.Printf("Failed to save to config file: %v\n", err)
 			return
 		}
@@ -527,8 +584,12 @@ var configShowCmd = &cobra.Command{
 			return
 		}
 
-		courses := loadExistingCourses()
-		if len(courses) == 0 {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("Failed to read config file:\n%v\n", err)
+			return
+		}
+		if len(cfg.Courses) == 0 {
 			fmt.Println("No courses found in the config.")
 			return
 		}
@@ -538,15 +599,16 @@ var configShowCmd = &cobra.Command{
 		fmt.Println("   [ ] indicates the course is *not* blacklisted.")
 		fmt.Println()
 
-		i := 1
-
-		for _, course := range courses {
+		for i, course := range cfg.Courses {
 			blMark := " "
 			if course.Blacklisted {
 				blMark = "X"
 			}
-			fmt.Printf("%d) [%s] %s - %s - %s\n", i, blMark, course.Name, course.URL, course.WebsiteType)
-			i++
+			tags := ""
+			if len(course.Tags) > 0 {
+				tags = " (" + strings.Join(course.Tags, ", ") + ")"
+			}
+			fmt.Printf("%d) [%s] %s - %s - %s%s\n", i+1, blMark, course.Name, course.URL, course.WebsiteType, tags)
 		}
 	},
 }
@@ -556,14 +618,17 @@ var configBlacklistCmd = &cobra.Command{
 	Use:   "blacklist",
 	Short: "Toggle blacklisted status so courses are skipped (or re-included) in 'ALL' searches",
 	Run: func(cmd *cobra.Command, args []string) {
-		// Load existing courses into a slice for stable ordering
-		existing := loadExistingCoursesSlice()
-		if len(existing) == 0 {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("Failed to read config file:\n%v\n", err)
+			return
+		}
+		if len(cfg.Courses) == 0 {
 			fmt.Println("No courses found in config.")
 			return
 		}
 
-		p := tea.NewProgram(initialBlacklistModel(existing), tea.WithAltScreen())
+		p := tea.NewProgram(initialBlacklistModel(cfg.Courses), tea.WithAltScreen())
 		m, err := p.Run()
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
@@ -571,13 +636,9 @@ var configBlacklistCmd = &cobra.Command{
 		}
 
 		model := m.(blacklistModel)
+		cfg.Courses = model.courses
 
-		if !CreateDir() {
-			return
-		}
-
-		err = overwriteCoursesToFile(model.courses)
-		if err != nil {
+		if err := config.Save(configPath, cfg); err != nil {
 			fmt.Printf("Failed to save updated blacklist status: %s\n", err)
 			return
 		}
@@ -589,13 +650,17 @@ var configDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete courses from the config file",
 	Run: func(cmd *cobra.Command, args []string) {
-		existing := loadExistingCoursesSlice()
-		if len(existing) == 0 {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("Failed to read config file:\n%v\n", err)
+			return
+		}
+		if len(cfg.Courses) == 0 {
 			fmt.Println("No courses found in config.")
 			return
 		}
 
-		p := tea.NewProgram(initialDeleteModel(existing), tea.WithAltScreen())
+		p := tea.NewProgram(initialDeleteModel(cfg.Courses), tea.WithAltScreen())
 		m, err := p.Run()
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
@@ -604,22 +669,20 @@ var configDeleteCmd = &cobra.Command{
 		model := m.(deleteModel)
 
 		// Build a slice with everything *not* selected
-		var remaining []CourseInfo
+		var remaining []config.Course
 		for i, c := range model.courses {
 			if _, remove := model.selected[i]; !remove {
 				remaining = append(remaining, c)
 			}
 		}
 
-		if len(remaining) == len(existing) {
+		if len(remaining) == len(cfg.Courses) {
 			fmt.Println("No courses were deleted.")
 			return
 		}
 
-		if !CreateDir() {
-			return
-		}
-		if err := overwriteCoursesToFile(remaining); err != nil {
+		cfg.Courses = remaining
+		if err := config.Save(configPath, cfg); err != nil {
 			fmt.Printf("Failed to save updated config: %v\n", err)
 			return
 		}
@@ -627,24 +690,11 @@ var configDeleteCmd = &cobra.Command{
 	},
 }
 
-// Helper to load into a slice instead of a map, so we can preserve an index
-func loadExistingCoursesSlice() []CourseInfo {
-	cMap := loadExistingCourses()
-	var out []CourseInfo
-	for _, c := range cMap {
-		out = append(out, c)
-	}
-	// (Optional) sort by c.Name or something so it’s stable
-	sort.Slice(out, func(i, j int) bool {
-		return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name)
-	})
-	return out
-}
-
 // Initialises the command and adds the -overwrite flag
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.Flags().BoolVarP(&overwrite, "overwrite", "o", false, "Overwrite the existing config")
+	configCmd.Flags().BoolVar(&verifyURLs, "verify", false, "Fetch each new course's booking page and only save the ones that work")
 
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configBlacklistCmd)
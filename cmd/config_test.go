@@ -6,10 +6,17 @@
 package cmd
 
 import (
+	"context"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/config"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fakesite"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -62,20 +69,20 @@ func TestConfigExists(t *testing.T) {
 	})
 }
 
-func TestLoadExistingConfig_NoFile(t *testing.T) {
+func TestLoadConfig_NoFile(t *testing.T) {
 	_, restore := withTempConfigPath(t, ".config/TeeTimeFinder/config.txt")
 	defer restore()
 
 	// Ensure file does not exist
 	require.False(t, ConfigExists(), "config file should not exist for this test")
 
-	response := loadExistingCourses()
-	require.NotNil(t, response, "returned map should not be nil")
-	assert.Empty(t, response, "expected empty map when no config file present")
+	cfg, err := loadConfig()
+	require.NoError(t, err, "a missing config is the same as an empty one")
+	assert.Empty(t, cfg.Courses, "expected no courses when no config file present")
 }
 
 // Load courses from testdata/config.txt
-func TestLoadExistingCourses(t *testing.T) {
+func TestLoadConfig(t *testing.T) {
 	// Point the global configPath at a real file in testdata.
 	old := configPath
 	configPath = filepath.Join("testdata", "config.txt")
@@ -83,137 +90,411 @@ func TestLoadExistingCourses(t *testing.T) {
 
 	require.FileExists(t, configPath, "expected test config file in testdata/")
 
-	response := loadExistingCourses()
-	require.NotNil(t, response, "returned map should not be nil")
+	cfg, err := loadConfig()
+	require.NoError(t, err)
 
-	// Expect exactly 4 valid entries keyed by URL
-	require.Len(t, response, 4, "expected three parsed courses")
+	// Courses come back in file order
+	names := make([]string, len(cfg.Courses))
+	for i, c := range cfg.Courses {
+		names[i] = c.Name
+	}
+	assert.Equal(t, []string{"Collier Park Golf Course", "Hamersley Golf Course", "Fremantle Golf Course", "The Springs Golf Course"}, names)
 
-	// Collier Park
-	collier, ok := response["https://bookings.collierparkgolf.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000"]
-	require.True(t, ok, "should contain Collier Park by URL key")
-	assert.Equal(t, "Collier Park Golf Course", collier.Name)
+	collier := cfg.Courses[0]
 	assert.Equal(t, "https://bookings.collierparkgolf.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000", collier.URL)
 	assert.Equal(t, "miclub", collier.WebsiteType)
 	assert.True(t, collier.Blacklisted, "blacklist flag should be true for Collier Park")
 
-	// Hamersley
-	hamersley, ok := response["https://hamersley.quick18.com/teetimes/searchmatrix"]
-	require.True(t, ok, "should contain Hamersley Golf Course")
-	assert.Equal(t, "Hamersley Golf Course", hamersley.Name)
+	hamersley := cfg.Courses[1]
+	assert.Equal(t, "https://hamersley.quick18.com/teetimes/searchmatrix", hamersley.URL)
 	assert.Equal(t, "Quick18", hamersley.WebsiteType)
 	assert.False(t, hamersley.Blacklisted)
+}
 
-	// Fremantle
-	freo, ok := response["https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000"]
-	require.True(t, ok, "should contain Fremantle Golf Course")
-	assert.Equal(t, "Fremantle Golf Course", freo.Name)
-	assert.Equal(t, "miclub", freo.WebsiteType)
-	assert.False(t, freo.Blacklisted)
+// The config commands and the search must agree on what's in the file
+func TestLoadCoursesMatchesConfig(t *testing.T) {
+	cfgPath, restore := withTempConfigPath(t, "config.txt")
+	defer restore()
 
-	// The Springs
-	springs, ok := response["https://springs.quick18.com/teetimes/searchmatrix"]
-	require.True(t, ok, "should contain The Springs Golf Course")
-	assert.Equal(t, "The Springs Golf Course", springs.Name)
-	assert.Equal(t, "Quick18", springs.WebsiteType)
-	assert.False(t, springs.Blacklisted)
+	content := "# my courses\n" +
+		"\n" +
+		"Collier Park Golf Course,https://bookings.collierparkgolf.com.au,miclub,TRUE\n" +
+		"#Hamersley Golf Course,https://hamersley.quick18.com/teetimes/searchmatrix,quick18\n" +
+		"The Springs Golf Course,https://springs.quick18.com/teetimes/searchmatrix,quick18\n"
+	require.NoError(t, os.WriteFile(cfgPath, []byte(content), 0o644))
+
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	courses, err := loadCourses()
+	require.NoError(t, err)
+
+	require.Len(t, courses, len(cfg.Courses), "commented out courses are skipped by both")
+	for _, c := range cfg.Courses {
+		got, ok := courses[c.Name]
+		require.True(t, ok, "search should see %s", c.Name)
+		assert.Equal(t, CourseConfig{URL: c.URL, WebsiteType: c.WebsiteType, Blacklisted: c.Blacklisted}, got)
+	}
+	assert.True(t, courses["Collier Park Golf Course"].Blacklisted)
 }
 
-func TestAppendCoursesToFile(t *testing.T) {
-	cfgPath, restore := withTempConfigPath(t, ".config/TeeTimeFinder/config.txt")
+func TestInvalidConfig(t *testing.T) {
+	cfgPath, restore := withTempConfigPath(t, "config.txt")
 	defer restore()
 
-	require.True(t, CreateDir(), "CreateDir must succeed for test")
-
-	// test cases
-	courses := []CourseInfo{
-		{
-			Name:        "Fremantle Golf Course",
-			URL:         "https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000",
-			WebsiteType: "miclub",
-			Blacklisted: false,
-		},
-		{
-			Name:        "The Springs Golf Course",
-			URL:         "https://springs.quick18.com/teetimes/searchmatrix",
-			WebsiteType: "Quick18",
-			Blacklisted: false,
-		},
-	}
+	content := "Collier Park Golf Course,https://bookings.collierparkgolf.com.au,miclub\n" +
+		"Hamersley Golf Course\n"
+	require.NoError(t, os.WriteFile(cfgPath, []byte(content), 0o644))
+
+	_, err := loadConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), cfgPath+":2:")
 
-	// Append both courses to new file
-	require.NoError(t, appendCoursesToFile(courses), "appendCoursesToFile should succeed")
+	courses, err := loadCourses()
+	require.Error(t, err, "the search refuses a broken config rather than guessing")
+	assert.Contains(t, err.Error(), cfgPath+":2:")
+	assert.Nil(t, courses)
+}
+
+func TestDefaultConfigPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(configEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	assert.Equal(t, filepath.Join(home, ".config", "TeeTimeFinder", "config.yaml"), defaultConfigPath())
+
+	t.Setenv("XDG_CONFIG_HOME", "relative/dir")
+	assert.Equal(t, filepath.Join(home, ".config", "TeeTimeFinder", "config.yaml"), defaultConfigPath(), "a relative XDG_CONFIG_HOME is ignored")
+
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	assert.Equal(t, filepath.Join(xdg, "TeeTimeFinder", "config.yaml"), defaultConfigPath())
 
-	// Verify file content
-	bytes, err := os.ReadFile(cfgPath)
-	require.NoError(t, err, "expected config file to exist after append")
-	expected := "" +
-		"Fremantle Golf Course,https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000,miclub,false\n" +
-		"The Springs Golf Course,https://springs.quick18.com/teetimes/searchmatrix,Quick18,false\n"
-	assert.Equal(t, expected, string(bytes), "file content should match expected lines")
+	t.Setenv(configEnv, "/srv/ci/courses.yaml")
+	assert.Equal(t, "/srv/ci/courses.yaml", defaultConfigPath(), "the env var beats XDG_CONFIG_HOME")
+}
+
+func TestConfigFlag(t *testing.T) {
+	_, restore := withTempConfigPath(t, "config.yaml")
+	defer restore()
+	defer func() { configFile = "" }()
 
-	// Verify results
-	response := loadExistingCourses()
-	require.Len(t, response, 2, "expected two courses parsed")
+	perth := filepath.Join(t.TempDir(), "perth.yaml")
+	require.NoError(t, config.Save(perth, &config.Config{Courses: []config.Course{
+		{Name: "Hamersley Golf Course", URL: "https://hamersley.quick18.com/teetimes/searchmatrix", WebsiteType: "quick18"},
+	}}))
 
-	freo := response["https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000"]
-	assert.Equal(t, "Fremantle Golf Course", freo.Name)
-	assert.Equal(t, "miclub", freo.WebsiteType)
-	assert.False(t, freo.Blacklisted)
+	configFile = perth
+	applyConfigFlag()
+	assert.Equal(t, perth, configPath)
 
-	springs := response["https://springs.quick18.com/teetimes/searchmatrix"]
-	assert.Equal(t, "The Springs Golf Course", springs.Name)
-	assert.Equal(t, "Quick18", springs.WebsiteType)
-	assert.False(t, springs.Blacklisted)
+	courses, err := loadCourses()
+	require.NoError(t, err)
+	assert.Contains(t, courses, "Hamersley Golf Course")
 }
 
-func TestOverwriteCoursesToFile(t *testing.T) {
-	cfgPath, restore := withTempConfigPath(t, ".config/TeeTimeFinder/config.txt")
+func TestMigrateConfig(t *testing.T) {
+	_, restore := withTempConfigPath(t, "config.yaml")
 	defer restore()
 
-	require.True(t, CreateDir(), "CreateDir must succeed for test")
-
-	// Seed the file with junk so we can prove it gets truncated.
-	require.NoError(t, os.WriteFile(cfgPath, []byte("JUNK SHOULDN'T SURVIVE\n"), 0o644))
-
-	// Overwrite with these two exact courses
-	courses := []CourseInfo{
-		{
-			Name:        "Fremantle Golf Course",
-			URL:         "https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000",
-			WebsiteType: "miclub",
-			Blacklisted: false,
-		},
-		{
-			Name:        "The Springs Golf Course",
-			URL:         "https://springs.quick18.com/teetimes/searchmatrix",
-			WebsiteType: "Quick18",
-			Blacklisted: false,
-		},
+	content := []byte("Hamersley Golf Course,https://hamersley.quick18.com/teetimes/searchmatrix,quick18\n")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(configEnv, "")
+	legacy := filepath.Join(home, ".config", "TeeTimeFinder", "config.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(legacy), 0o755))
+
+	t.Run("Default location", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "")
+		require.NoError(t, os.WriteFile(legacy, content, 0o644))
+		configPath = defaultConfigPath()
+		defer os.Remove(configPath)
+
+		require.NoError(t, migrateConfig())
+		assert.FileExists(t, legacy+".bak", "the old config is kept as a backup")
+
+		courses, err := loadCourses()
+		require.NoError(t, err)
+		assert.Equal(t, CourseConfig{URL: "https://hamersley.quick18.com/teetimes/searchmatrix", WebsiteType: "quick18"}, courses["Hamersley Golf Course"])
+	})
+
+	t.Run("XDG_CONFIG_HOME", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		require.NoError(t, os.WriteFile(legacy, content, 0o644))
+		configPath = defaultConfigPath()
+
+		require.NoError(t, migrateConfig())
+		assert.NoFileExists(t, legacy, "the old config in ~/.config is found")
+
+		courses, err := loadCourses()
+		require.NoError(t, err)
+		assert.Contains(t, courses, "Hamersley Golf Course")
+	})
+
+	t.Run("Somewhere else", func(t *testing.T) {
+		configPath = filepath.Join(t.TempDir(), "perth.yaml")
+		unrelated := filepath.Join(filepath.Dir(configPath), "config.txt")
+		require.NoError(t, os.WriteFile(unrelated, content, 0o644))
+
+		require.NoError(t, migrateConfig())
+		assert.FileExists(t, unrelated, "a config.txt next to --config isn't touched")
+		assert.NoFileExists(t, configPath)
+	})
+}
+
+func TestApplyConfigDefaults(t *testing.T) {
+	cfgPath, restore := withTempConfigPath(t, "config.yaml")
+	defer restore()
+
+	oldTime, oldSpots, oldDays, oldWorkers, oldDefaults := specifiedTime, specifiedSpots, searchDays, scrapeWorkers, configDefaults
+	defer func() {
+		specifiedTime, specifiedSpots, searchDays, scrapeWorkers, configDefaults = oldTime, oldSpots, oldDays, oldWorkers, oldDefaults
+	}()
+	specifiedTime, specifiedSpots, searchDays, scrapeWorkers = "", 0, 0, defaultWorkers
+
+	content := "version: 1\ndefaults:\n  time: \"07:30\"\n  spots: 2\n  days: 3\n  workers: 2\ncourses: []\n"
+	require.NoError(t, os.WriteFile(cfgPath, []byte(content), 0o644))
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVarP(&specifiedTime, "time", "t", "", "")
+	cmd.Flags().IntVarP(&specifiedSpots, "spots", "s", 0, "")
+	cmd.Flags().IntVar(&searchDays, "days", 0, "")
+	cmd.Flags().String("from", "", "")
+	cmd.Flags().String("to", "", "")
+	cmd.Flags().IntVarP(&scrapeWorkers, "workers", "w", defaultWorkers, "")
+	require.NoError(t, cmd.ParseFlags([]string{"--spots", "4", "--from", "01-01-2030"}))
+
+	applyConfigDefaults(cmd)
+	assert.Equal(t, "07:30", configDefaults.Time)
+	assert.Empty(t, specifiedTime, "the default isn't treated as a flag")
+	assert.Equal(t, 0, configDefaults.Spots, "a flag on the command line beats the default")
+	assert.Equal(t, 4, specifiedSpots)
+	assert.Equal(t, 0, configDefaults.Days, "the default days don't apply to a --from/--to range")
+	assert.Equal(t, 2, scrapeWorkers)
+}
+
+func TestStartFormDefaults(t *testing.T) {
+	oldTime, oldSpots, oldDefaults, oldCourses, oldGroups := specifiedTime, specifiedSpots, configDefaults, courseList, groupList
+	defer func() {
+		specifiedTime, specifiedSpots, configDefaults, courseList, groupList = oldTime, oldSpots, oldDefaults, oldCourses, oldGroups
+	}()
+	specifiedTime, specifiedSpots, courseList, groupList = "", 0, nil, nil
+	configDefaults = config.Defaults{Time: "07:30", Spots: 2}
+
+	// The defaults are filled in but can still be changed
+	m := newStartFormModel(map[string]CourseConfig{"A": {}})
+	assert.Equal(t, "07:30", m.in[2].Value())
+	assert.Equal(t, "2", m.in[3].Value())
+	assert.False(t, m.locked[2])
+	assert.False(t, m.locked[3])
+
+	t.Run("Left alone", func(t *testing.T) {
+		applyStartAnswers(startAnswers{time: "07:30", spots: "2"})
+		assert.Empty(t, specifiedTime, "still a default, so it can be skipped once it's gone by")
+		assert.Equal(t, "07:30", configDefaults.Time)
+	})
+
+	t.Run("Changed and cleared", func(t *testing.T) {
+		applyStartAnswers(startAnswers{time: "09:00", spots: ""})
+		assert.Equal(t, "09:00", specifiedTime)
+		assert.Empty(t, configDefaults.Time)
+		assert.Equal(t, 0, specifiedSpots)
+		assert.Equal(t, 0, configDefaults.Spots, "clearing the spots drops the default")
+	})
+}
+
+func TestConfigModelDetectsProvider(t *testing.T) {
+	m := initialConfigModel(nil)
+	m.inputs[0].SetValue("Hamersley Golf Course")
+	m.focusIndex = 1
+	m.inputs[1].SetValue("https://hamersley.quick18.com/teetimes/searchmatrix?teedate=20250211")
+
+	// Moving off the URL tidies it and fills in the website type
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = next.(configModel)
+	assert.Equal(t, 2, m.focusIndex)
+	assert.Equal(t, "https://hamersley.quick18.com/teetimes/searchmatrix", m.inputs[1].Value())
+	assert.Equal(t, "quick18", m.inputs[2].Value())
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(configModel)
+	require.NoError(t, m.err)
+	require.Len(t, m.courses, 1)
+	assert.Equal(t, config.Course{Name: "Hamersley Golf Course", URL: "https://hamersley.quick18.com/teetimes/searchmatrix", WebsiteType: "quick18"}, m.courses[0])
+
+	// A type that was typed in isn't replaced, but one the URL isn't for is
+	// turned away
+	m.inputs[0].SetValue("Collier Park Golf Course")
+	m.inputs[1].SetValue("https://bookings.collierparkgolf.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000&selectedDate=2025-02-11")
+	m.inputs[2].SetValue("quick18")
+	m.focusIndex = 2
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(configModel)
+	assert.EqualError(t, m.err, "The URL is a miclub booking page, not quick18")
+	assert.Equal(t, "quick18", m.inputs[2].Value())
+	require.Len(t, m.courses, 1)
+
+	m.inputs[2].SetValue("MiClub")
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(configModel)
+	require.NoError(t, m.err)
+	require.Len(t, m.courses, 2)
+	assert.Equal(t, "miclub", m.courses[1].WebsiteType)
+	assert.Equal(t, "https://bookings.collierparkgolf.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000", m.courses[1].URL)
+
+	// A URL nothing recognises can have any known type
+	m.inputs[0].SetValue("Somewhere Golf Course")
+	m.inputs[1].SetValue("https://example.com/bookings")
+	m.inputs[2].SetValue("stub")
+	m.focusIndex = 2
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(configModel)
+	require.NoError(t, m.err)
+	require.Len(t, m.courses, 3)
+	assert.Equal(t, "stub", m.courses[2].WebsiteType)
+}
+
+func TestConfigModelRejectsDuplicates(t *testing.T) {
+	m := initialConfigModel([]config.Course{
+		{Name: "Hamersley Golf Course", URL: "https://hamersley.quick18.com/teetimes/searchmatrix", WebsiteType: "quick18", Aliases: []string{"hamo"}},
+	})
+	add := func(name, courseURL string) {
+		t.Helper()
+		m.inputs[0].SetValue(name)
+		m.inputs[1].SetValue(courseURL)
+		m.inputs[2].SetValue("")
+		m.focusIndex = 2
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = next.(configModel)
 	}
-	require.NoError(t, overwriteCoursesToFile(courses), "overwriteCoursesToFile should truncate+write")
-
-	// Verify file content is exactly the two lines
-	gotBytes, err := os.ReadFile(cfgPath)
-	require.NoError(t, err, "expected config file to exist after overwrite")
-	expected := "" +
-		"Fremantle Golf Course,https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000,miclub,false\n" +
-		"The Springs Golf Course,https://springs.quick18.com/teetimes/searchmatrix,Quick18,false\n"
-	assert.Equal(t, expected, string(gotBytes), "file content should match expected lines exactly")
-
-	// Verify results
-	response := loadExistingCourses()
-	require.Len(t, response, 2, "expected two courses parsed after overwrite")
-
-	freo := response["https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000"]
-	require.NotNil(t, freo)
-	assert.Equal(t, "Fremantle Golf Course", freo.Name)
-	assert.Equal(t, "miclub", freo.WebsiteType)
-	assert.False(t, freo.Blacklisted)
-
-	springs := response["https://springs.quick18.com/teetimes/searchmatrix"]
-	require.NotNil(t, springs)
-	assert.Equal(t, "The Springs Golf Course", springs.Name)
-	assert.Equal(t, "Quick18", springs.WebsiteType)
-	assert.False(t, springs.Blacklisted)
+
+	add("hamersley golf course", "https://hamersley.quick18.com/teetimes/searchmatrix")
+	assert.EqualError(t, m.err, "There's already a course called Hamersley Golf Course")
+	assert.Empty(t, m.courses)
+
+	add("Hamo", "https://hamersley.quick18.com/teetimes/searchmatrix")
+	assert.EqualError(t, m.err, "Hamersley Golf Course already has the alias hamo")
+
+	add("Collier Park Golf Course", "https://bookings.collierparkgolf.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000")
+	require.NoError(t, m.err)
+	add("Collier Park Golf Course", "https://bookings.collierparkgolf.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000")
+	assert.EqualError(t, m.err, "There's already a course called Collier Park Golf Course", "one added earlier in the same go counts too")
+	assert.Len(t, m.courses, 1)
+}
+
+func TestEditModel(t *testing.T) {
+	courses := []config.Course{
+		{Name: "Hamersley Golf Course", URL: "https://hamersley.quick18.com/teetimes/searchmatrix", WebsiteType: "quick18"},
+		{Name: "Collier Park Golf Course", URL: "https://bookings.collierparkgolf.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000", WebsiteType: "miclub", Aliases: []string{"collier"}},
+	}
+	m := initialEditModel(append([]config.Course(nil), courses...))
+
+	press := func(msg tea.KeyMsg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(editModel)
+	}
+
+	// Pick the second course and change its URL, the type is left alone
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, 1, m.editing)
+	assert.Equal(t, "Collier Park Golf Course", m.inputs[editName].Value())
+
+	m.focus = editURL
+	m.inputs[editURL].SetValue("https://bookings.collierparkgolf.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000001&selectedDate=2025-02-11")
+	press(tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, "https://bookings.collierparkgolf.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000001", m.inputs[editURL].Value())
+	assert.Equal(t, "miclub", m.inputs[editType].Value())
+
+	// Tag and blacklist it and keep the changes
+	m.inputs[editTags].SetValue("south, , coast")
+	m.focus = editBlacklisted
+	press(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NoError(t, m.err)
+	assert.Equal(t, -1, m.editing)
+	assert.True(t, m.changed)
+	assert.True(t, m.courses[1].Blacklisted)
+	assert.Equal(t, []string{"south", "coast"}, m.courses[1].Tags)
+	assert.Equal(t, "https://bookings.collierparkgolf.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000001", m.courses[1].URL)
+	assert.Equal(t, courses[0], m.courses[0])
+
+	t.Run("Rejects a bad edit", func(t *testing.T) {
+		m := initialEditModel(append([]config.Course(nil), courses...))
+		m.startEditing(0)
+
+		m.inputs[editName].SetValue("collier park golf course")
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+		m = next.(editModel)
+		assert.ErrorContains(t, m.err, "already a course called Collier Park Golf Course")
+		assert.Equal(t, 0, m.editing)
+
+		m.inputs[editName].SetValue("Hamersley")
+		m.inputs[editAliases].SetValue("hamo, Collier")
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+		m = next.(editModel)
+		assert.ErrorContains(t, m.err, "Collier Park Golf Course already has the alias collier")
+		m.inputs[editAliases].SetValue("")
+
+		m.inputs[editName].SetValue("Hamersley")
+		m.inputs[editType].SetValue("golfnow")
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+		m = next.(editModel)
+		assert.ErrorContains(t, m.err, "Invalid website type")
+
+		m.inputs[editType].SetValue("quick18")
+		m.inputs[editURL].SetValue(courses[1].URL)
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+		m = next.(editModel)
+		assert.EqualError(t, m.err, "The URL is a miclub booking page, not quick18")
+
+		// Backing out throws the edit away
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		m = next.(editModel)
+		assert.Equal(t, -1, m.editing)
+		assert.False(t, m.changed)
+		assert.Equal(t, courses, m.courses)
+	})
+}
+
+func TestFindEditCourse(t *testing.T) {
+	cfg := &config.Config{Courses: []config.Course{
+		{Name: "Fremantle Public Golf Course", Aliases: []string{"freo"}},
+		{Name: "Royal Fremantle Golf Club"},
+		{Name: "Hamersley Golf Course", Aliases: []string{"hamo"}},
+	}}
+
+	for typed, want := range map[string]int{"hamersley golf course": 2, "hamo": 2, "hamersly": 2, "freo": 0, "royal": 1} {
+		index, err := findEditCourse(cfg, typed)
+		require.NoError(t, err, typed)
+		assert.Equal(t, want, index, typed)
+	}
+
+	_, err := findEditCourse(cfg, "fremantle")
+	assert.EqualError(t, err, `course 'fremantle' matches more than one course, did you mean "Fremantle Public Golf Course" or "Royal Fremantle Golf Club"`)
+	_, err = findEditCourse(cfg, "nowhere")
+	assert.EqualError(t, err, "course 'nowhere' does not exist in config")
+}
+
+func TestVerifyNewCourses(t *testing.T) {
+	site := fakesite.New(fakesite.Course{
+		Platform: fakesite.MiClub,
+		ID:       "3000000",
+		Times:    map[string]map[string][]fakesite.TeeTime{"2025-10-18": {"18 Holes": nil}},
+	})
+	srv := httptest.NewServer(site)
+	defer srv.Close()
+
+	origDelay, origNoCache := fetchDelay, noCache
+	defer func() { fetchDelay, noCache = origDelay, origNoCache }()
+	fetchDelay, noCache = 0, true
+
+	courseURL := site.CourseURL(srv.URL, "3000000")
+	ok := verifyNewCourses(context.Background(), []config.Course{
+		{Name: "Collier Park", URL: courseURL, WebsiteType: "miclub"},
+		{Name: "Wrong Type", URL: courseURL, WebsiteType: "quick18"},
+		{Name: "Unknown Type", URL: courseURL, WebsiteType: "golfnow"},
+	})
+	require.Len(t, ok, 1, "courses the website type can't read aren't kept")
+	assert.Equal(t, "Collier Park", ok[0].Name)
 }
@@ -0,0 +1,397 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/config"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+// The fields on the edit form, in the order they're shown
+const (
+	editName = iota
+	editAliases
+	editURL
+	editType
+	editTags
+	editNotes
+	editBlacklisted // a toggle rather than a text input
+	editFields
+)
+
+type editItem struct {
+	course config.Course
+}
+
+func (i editItem) Title() string { return i.course.Name }
+func (i editItem) Description() string {
+	return fmt.Sprintf("%s (%s)", i.course.URL, i.course.WebsiteType)
+}
+func (i editItem) FilterValue() string { return i.course.Name }
+
+type editDelegate struct{}
+
+func (d editDelegate) Height() int                               { return 2 }
+func (d editDelegate) Spacing() int                              { return 1 }
+func (d editDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd { return nil }
+
+func (d editDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	item := listItem.(editItem)
+
+	style := defaultStyle
+	if index == m.Index() {
+		style = hoverStyle
+	}
+
+	name := item.course.Name
+	if item.course.Blacklisted {
+		name += " " + blacklistStyle.Render("(blacklisted)")
+	}
+	title := style.Bold(true).Render(name)
+	desc := style.Render(item.Description())
+
+	fmt.Fprintf(w, "%s\n%s", title, desc)
+}
+
+// editModel lists the courses and opens a form to change the one picked.
+// Changes are only kept in courses until the caller saves them.
+type editModel struct {
+	list    list.Model
+	courses []config.Course
+	editing int // index of the course on the form, -1 while picking
+
+	inputs      []textinput.Model // name, aliases, URL, type, tags and notes
+	blacklisted bool
+	focus       int
+
+	changed   bool
+	cancelled bool
+	err       error
+	success   string
+}
+
+func initialEditModel(courses []config.Course) editModel {
+	items := make([]list.Item, len(courses))
+	for i, c := range courses {
+		items[i] = editItem{course: c}
+	}
+
+	l := list.New(items, editDelegate{}, 0, 0)
+	l.Title = "Pick a course to edit (enter to edit, esc to save and quit, ctrl+c to quit without saving)"
+	l.Styles.Title = titleStyle
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+
+	inputs := make([]textinput.Model, editBlacklisted)
+	for i := range inputs {
+		t := textinput.New()
+		t.CharLimit = 512
+		t.Width = 300
+		t.PromptStyle = defaultStyle
+		t.TextStyle = defaultStyle
+		switch i {
+		case editName:
+			t.Placeholder = "Course Name"
+		case editAliases:
+			t.Placeholder = "Aliases, comma-sep (optional, other names to search it by)"
+		case editURL:
+			t.Placeholder = "Course URL"
+		case editType:
+			t.Placeholder = "Website Type (MiClub or Quick18, filled in from the URL)"
+		case editTags:
+			t.Placeholder = "Tags, comma-sep (optional, e.g. south, country)"
+		case editNotes:
+			t.Placeholder = "Notes (optional)"
+		}
+		inputs[i] = t
+	}
+
+	return editModel{
+		list:    l,
+		courses: courses,
+		editing: -1,
+		inputs:  inputs,
+	}
+}
+
+// Helper function to open the form for a course
+func (m *editModel) startEditing(index int) tea.Cmd {
+	c := m.courses[index]
+	m.editing = index
+	m.inputs[editName].SetValue(c.Name)
+	m.inputs[editAliases].SetValue(strings.Join(c.Aliases, ", "))
+	m.inputs[editURL].SetValue(c.URL)
+	m.inputs[editType].SetValue(c.WebsiteType)
+	m.inputs[editTags].SetValue(strings.Join(c.Tags, ", "))
+	m.inputs[editNotes].SetValue(c.Notes)
+	m.blacklisted = c.Blacklisted
+	m.focus = editName
+	m.err = nil
+	m.success = ""
+	return m.focusInputs()
+}
+
+func (m editModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m editModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.list.SetSize(size.Width, size.Height-2)
+	}
+	if m.editing >= 0 {
+		return m.updateForm(msg)
+	}
+
+	var cmd tea.Cmd
+	if k, ok := msg.(tea.KeyMsg); ok {
+		switch k.String() {
+		case "ctrl+c":
+			m.cancelled = true
+			return m, tea.Quit
+		case "esc", "q":
+			return m, tea.Quit
+		case "enter":
+			if len(m.courses) == 0 {
+				return m, nil
+			}
+			return m, m.startEditing(m.list.Index())
+		}
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// Helper function to handle keys while the form is open
+func (m editModel) updateForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	if m.focus < editBlacklisted {
+		m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	}
+
+	k, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, cmd
+	}
+	leaving := m.focus
+
+	switch k.String() {
+	case "ctrl+c":
+		m.cancelled = true
+		return m, tea.Quit
+
+	case "esc":
+		// back to the list without keeping this course's changes
+		m.editing = -1
+		m.err = nil
+		return m, nil
+
+	case "up", "shift+tab":
+		m.focus = (m.focus + editFields - 1) % editFields
+
+	case "down", "tab":
+		m.focus = (m.focus + 1) % editFields
+
+	case " ":
+		if m.focus == editBlacklisted {
+			m.blacklisted = !m.blacklisted
+		}
+
+	case "ctrl+s":
+		return m, m.apply()
+
+	case "enter":
+		if m.focus == editBlacklisted {
+			return m, m.apply()
+		}
+		m.focus++
+	}
+
+	// Once the URL is in, tidy it and fill in the website type
+	if leaving == editURL && m.focus != editURL {
+		m.detectFromURL()
+	}
+	return m, tea.Batch(cmd, m.focusInputs())
+}
+
+// Helper function to check the form and, if it's OK, put the changes back
+// into the list
+func (m *editModel) apply() tea.Cmd {
+	m.detectFromURL()
+
+	edited := m.courses[m.editing]
+	edited.Name = strings.TrimSpace(m.inputs[editName].Value())
+	edited.Aliases = splitTags(m.inputs[editAliases].Value())
+	edited.URL = strings.TrimSpace(m.inputs[editURL].Value())
+	edited.WebsiteType = strings.ToLower(strings.TrimSpace(m.inputs[editType].Value()))
+	edited.Tags = splitTags(m.inputs[editTags].Value())
+	edited.Notes = strings.TrimSpace(m.inputs[editNotes].Value())
+	edited.Blacklisted = m.blacklisted
+
+	if err := m.validate(edited); err != nil {
+		m.err = err
+		m.success = ""
+		return nil
+	}
+
+	if !reflect.DeepEqual(edited, m.courses[m.editing]) {
+		m.courses[m.editing] = edited
+		m.list.SetItem(m.editing, editItem{course: edited})
+		m.changed = true
+		m.success = fmt.Sprintf("[SUCCESS] Updated %s", edited.Name)
+	}
+	m.editing = -1
+	m.err = nil
+	return nil
+}
+
+// Helper function to check an edited course against the rest of the config
+func (m *editModel) validate(edited config.Course) error {
+	if err := edited.Validate(); err != nil {
+		return err
+	}
+	if err := checkWebsiteType(edited); err != nil {
+		return err
+	}
+	others := append(append([]config.Course(nil), m.courses[:m.editing]...), m.courses[m.editing+1:]...)
+	return checkNameFree(others, edited)
+}
+
+// Helper function to normalise the URL and prefill the website type from
+// it, leaving a type that's already there alone
+func (m *editModel) detectFromURL() {
+	detectFromURL(&m.inputs[editURL], &m.inputs[editType])
+}
+
+// Helper function to focus the input under the cursor and style the rest
+func (m *editModel) focusInputs() tea.Cmd {
+	var cmds []tea.Cmd
+	for i := range m.inputs {
+		if i == m.focus {
+			cmds = append(cmds, m.inputs[i].Focus())
+			m.inputs[i].PromptStyle = hoverStyle
+			m.inputs[i].TextStyle = hoverStyle
+		} else {
+			m.inputs[i].Blur()
+			m.inputs[i].PromptStyle = defaultStyle
+			m.inputs[i].TextStyle = defaultStyle
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m editModel) View() string {
+	if m.editing < 0 {
+		view := m.list.View()
+		if m.success != "" {
+			view += "\n" + successStyle.Render(m.success)
+		}
+		return view
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Editing "+m.courses[m.editing].Name) + "\n\n")
+	for i := range m.inputs {
+		b.WriteString(m.inputs[i].View())
+		b.WriteRune('\n')
+	}
+
+	toggle := "[ ] Blacklisted"
+	if m.blacklisted {
+		toggle = "[X] Blacklisted"
+	}
+	style := defaultStyle
+	if m.focus == editBlacklisted {
+		style = hoverStyle
+	}
+	b.WriteString(style.Render(toggle) + "\n\n")
+
+	if m.err != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("[Error] %s", m.err)) + "\n")
+	}
+	b.WriteString(controlStyle.Render("[Enter] next, [Space] toggle blacklisted, [Ctrl+S] or [Enter] on the last field to keep the changes, [Esc] back") + "\n")
+	return b.String()
+}
+
+// Helper function to find the course named on the command line, matched the
+// same way as -c so an alias or a small typo finds it too
+func findEditCourse(cfg *config.Config, typed string) (int, error) {
+	name, suggestions := matchCourse(courseConfigs(cfg), typed)
+	if name == "" {
+		return -1, courseMatchError(typed, suggestions)
+	}
+	for i, c := range cfg.Courses {
+		if c.Name == name {
+			return i, nil
+		}
+	}
+	return -1, courseMatchError(typed, nil)
+}
+
+var configEditCmd = &cobra.Command{
+	Use:               "edit [course]",
+	Short:             "Change a course's name, aliases, URL, website type, tags, notes or blacklisting",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeCourseArg,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load(configPath)
+		if errors.Is(err, os.ErrNotExist) || (err == nil && len(cfg.Courses) == 0) {
+			fmt.Println("No courses found in config.")
+			return
+		}
+		if err != nil {
+			fmt.Printf("Failed to read config file:\n%v\n", err)
+			return
+		}
+
+		model := initialEditModel(cfg.Courses)
+		if len(args) == 1 {
+			index, err := findEditCourse(cfg, args[0])
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			model.list.Select(index)
+			model.startEditing(index)
+		}
+
+		p := tea.NewProgram(model, tea.WithAltScreen())
+		m, err := p.Run()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		model = m.(editModel)
+
+		if model.cancelled || !model.changed {
+			fmt.Println("No changes were made.")
+			return
+		}
+
+		cfg.Courses = model.courses
+		if err := config.Save(configPath, cfg); err != nil {
+			fmt.Printf("Failed to save updated config: %v\n", err)
+			return
+		}
+		fmt.Println("Changes saved!")
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configEditCmd)
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/miclub"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/quick18"
+)
+
+var demoMode bool
+
+// Shown with the results so nobody tries to book a demo tee time
+const demoNotice = "Demo mode: these tee times come from saved sample pages, not the live booking sites."
+
+// demoCourse is one course in the built-in demo and the saved pages it is
+// served from
+type demoCourse struct {
+	name        string
+	url         string
+	websiteType string
+	pages       fs.FS
+	datesPage   string // the calendar (MiClub) or searchmatrix (Quick18)
+	timesPage   string // the timesheet, MiClub only
+}
+
+var demoCourses = []demoCourse{
+	{
+		name:        "Collier Park Golf Course",
+		url:         "https://bookings.collierparkgolf.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000",
+		websiteType: "miclub",
+		pages:       miclub.SamplePages,
+		datesPage:   "testdata/collier_park_dates.html",
+		timesPage:   "testdata/collier_park_timesheet.html",
+	},
+	{
+		name:        "Fremantle Public Golf Course",
+		url:         "https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000",
+		websiteType: "miclub",
+		pages:       miclub.SamplePages,
+		datesPage:   "testdata/fremantle_public_dates.html",
+		timesPage:   "testdata/fremantle_public_timesheet.html",
+	},
+	{
+		name:        "Hamersley Golf Course",
+		url:         "https://hamersley.quick18.com/teetimes/searchmatrix",
+		websiteType: "quick18",
+		pages:       quick18.SamplePages,
+		datesPage:   "testdata/hamersley.html",
+	},
+	{
+		name:        "The Springs Golf Course",
+		url:         "https://springs.quick18.com/teetimes/searchmatrix",
+		websiteType: "quick18",
+		pages:       quick18.SamplePages,
+		datesPage:   "testdata/the_springs.html",
+	},
+}
+
+// Helper function to get the demo courses in the same shape as the config
+func demoCourseConfigs() map[string]CourseConfig {
+	courses := make(map[string]CourseConfig, len(demoCourses))
+	for _, c := range demoCourses {
+		courses[c.name] = CourseConfig{URL: c.url, WebsiteType: c.websiteType}
+	}
+	return courses
+}
+
+// demoTransport answers the scrapers' requests from the saved pages instead
+// of the network. The pages are the same whatever date is asked for, so
+// every day of the demo looks like the day they were saved.
+type demoTransport struct{}
+
+func (demoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	page, pages, ok := demoPage(req.URL.Hostname(), req.URL.Path)
+	if !ok {
+		return nil, fmt.Errorf("demo mode has no page for %s", req.URL)
+	}
+	body, err := fs.ReadFile(pages, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Helper function to pick the saved page for a request to a demo course
+func demoPage(host, path string) (string, fs.FS, bool) {
+	for _, c := range demoCourses {
+		if !strings.Contains(c.url, "://"+host+"/") {
+			continue
+		}
+		if c.timesPage != "" && strings.HasSuffix(path, "/ViewPublicTimesheet.msp") {
+			return c.timesPage, c.pages, true
+		}
+		return c.datesPage, c.pages, true
+	}
+	return "", nil, false
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDemoPage(t *testing.T) {
+	page, _, ok := demoPage("fremantlepublic.miclub.com.au", "/guests/bookings/ViewPublicCalendar.msp")
+	assert.True(t, ok)
+	assert.Equal(t, "testdata/fremantle_public_dates.html", page)
+
+	page, _, ok = demoPage("fremantlepublic.miclub.com.au", "/guests/bookings/ViewPublicTimesheet.msp")
+	assert.True(t, ok)
+	assert.Equal(t, "testdata/fremantle_public_timesheet.html", page)
+
+	page, _, ok = demoPage("springs.quick18.com", "/teetimes/searchmatrix")
+	assert.True(t, ok)
+	assert.Equal(t, "testdata/the_springs.html", page)
+
+	_, _, ok = demoPage("example.com", "/teetimes/searchmatrix")
+	assert.False(t, ok, "only the demo courses are served")
+}
+
+func TestDemoSearch(t *testing.T) {
+	day := time.Now().AddDate(0, 0, 3)
+
+	// Save and restore globals
+	origDemo, origPath, origDate, origTime, origSpots, origCourses, origFormat := demoMode, configPath, specifiedDate, specifiedTime, specifiedSpots, courseList, outputFormat
+	defer func() {
+		demoMode, configPath, specifiedDate, specifiedTime, specifiedSpots, courseList, outputFormat = origDemo, origPath, origDate, origTime, origSpots, origCourses, origFormat
+	}()
+	demoMode = true
+	configPath = filepath.Join(t.TempDir(), "missing", "config.txt") // no config needed
+	specifiedDate = day.Format("02-01-2006")
+	specifiedTime, specifiedSpots, courseList = "", 0, nil
+	outputFormat = "json"
+
+	var out, errOut bytes.Buffer
+	code := runSearch(context.Background(), &out, &errOut)
+	assert.Equal(t, exitFound, code)
+	assert.Equal(t, demoNotice+"\n", errOut.String(), "every demo course should work")
+
+	var results []searchResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &results))
+	found := make(map[string]bool)
+	for _, r := range results {
+		found[r.Course] = true
+		assert.Equal(t, day.Format("2006-01-02"), r.Date, "the saved pages stand in for any date")
+	}
+	assert.Len(t, found, len(demoCourses))
+}
@@ -0,0 +1,303 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fakesite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests run whole searches through the real MiClub and Quick18
+// scrapers against a fake booking site, so nothing touches the internet.
+
+// fakeCourse is a course to put in the test config and on the fake site
+type fakeCourse struct {
+	name string
+	fakesite.Course
+}
+
+// Helper function to serve the courses on a fake site, point a temporary
+// config at them and reset the search flags. Everything is put back when
+// the test ends.
+func withFakeSite(t *testing.T, courses ...fakeCourse) *fakesite.Site {
+	site := fakesite.New()
+	for _, c := range courses {
+		site.Add(c.Course)
+	}
+	srv := httptest.NewServer(site)
+	t.Cleanup(srv.Close)
+
+	var config strings.Builder
+	for _, c := range courses {
+		fmt.Fprintf(&config, "%s,%s,%s,false\n", c.name, site.CourseURL(srv.URL, c.ID), c.Platform)
+	}
+	tmpConfig := filepath.Join(t.TempDir(), "config.txt")
+	require.NoError(t, os.WriteFile(tmpConfig, []byte(config.String()), 0644))
+
+	origPath, origDate, origTime, origSpots, origCourses, origFormat := configPath, specifiedDate, specifiedTime, specifiedSpots, courseList, outputFormat
+	origFrom, origTo, origDays, origTimeout, origNoCache, origDelay := fromDate, toDate, searchDays, courseTimeout, noCache, fetchDelay
+	t.Cleanup(func() {
+		configPath, specifiedDate, specifiedTime, specifiedSpots, courseList, outputFormat = origPath, origDate, origTime, origSpots, origCourses, origFormat
+		fromDate, toDate, searchDays, courseTimeout, noCache, fetchDelay = origFrom, origTo, origDays, origTimeout, origNoCache, origDelay
+	})
+	configPath = tmpConfig
+	specifiedDate, specifiedTime, specifiedSpots, courseList = "", "", 0, nil
+	fromDate, toDate, searchDays = "", "", 0
+	courseTimeout = defaultCourseTimeout
+	noCache = false
+	fetchDelay = 0 // every course is on the same host here
+	outputFormat = "json"
+
+	return site
+}
+
+// Helper function to run `search` and decode its JSON output
+func runJSONSearch(t *testing.T) (int, []searchResult, string) {
+	var out, errOut bytes.Buffer
+	code := runSearch(context.Background(), &out, &errOut)
+
+	var results []searchResult
+	if out.Len() > 0 {
+		require.NoError(t, json.Unmarshal(out.Bytes(), &results), out.String())
+	}
+	return code, results, errOut.String()
+}
+
+func TestEndToEndSearch(t *testing.T) {
+	day := time.Now().AddDate(0, 0, 2)
+	dayISO := day.Format("2006-01-02")
+
+	site := withFakeSite(t,
+		fakeCourse{"North Golf Club", fakesite.Course{
+			Platform: fakesite.MiClub,
+			ID:       "3000000",
+			Times: map[string]map[string][]fakesite.TeeTime{dayISO: {
+				"18 Holes": {
+					{Time: "07:00", Layout: "Main", Spots: 4, Fee: 49},
+					{Time: "07:30", Layout: "Main", Spots: 1, Fee: 49},
+					{Time: "14:30", Layout: "Main", Spots: 2, Fee: 49},
+				},
+				"9 Holes": {{Time: "06:00", Layout: "Front 9", Spots: 0, Fee: 30}},
+			}},
+		}},
+		fakeCourse{"South Golf Course", fakesite.Course{
+			Platform: fakesite.Quick18,
+			ID:       "south",
+			Times: map[string]map[string][]fakesite.TeeTime{dayISO: {
+				"9 Holes":  {{Time: "06:07", Layout: "Back 9 Morning", Spots: 2, Fee: 33}},
+				"18 holes": {{Time: "07:15", Layout: "Full", Spots: 4, Fee: 55}},
+			}},
+		}},
+	)
+	specifiedDate = day.Format("02-01-2006")
+	specifiedTime = "07:00"
+	specifiedSpots = 2
+
+	code, results, errOut := runJSONSearch(t)
+	assert.Equal(t, exitFound, code)
+	assert.Empty(t, errOut)
+
+	require.Len(t, results, 3, "the 1 spot and afternoon times should be filtered out")
+	assert.Equal(t, "06:07", results[0].Time)
+	assert.Equal(t, "South Golf Course", results[0].Course)
+	assert.Equal(t, "9 Holes", results[0].Game)
+	assert.Equal(t, "Back 9 Morning", results[0].Layout)
+	assert.Contains(t, results[0].BookingURL, "/south/teetimes/teetime/")
+
+	assert.Equal(t, searchResult{Date: dayISO, Time: "07:00", Course: "North Golf Club", Game: "18 Holes", Layout: "Main", Spots: 4}, withoutExtras(results[1]))
+	require.Len(t, results[1].Fees, 1)
+	assert.Equal(t, 49.0, results[1].Fees[0].Amount)
+	assert.Equal(t, "07:15", results[2].Time)
+	assert.Equal(t, "18 Holes", results[2].Game, "Quick18 game names are normalised like MiClub's")
+
+	// Searching again straight away uses the cache
+	requests := site.Requests()
+	code, again, _ := runJSONSearch(t)
+	assert.Equal(t, exitFound, code)
+	assert.Equal(t, results, again)
+	assert.Equal(t, requests, site.Requests(), "nothing should be fetched twice within the cache TTL")
+
+	// Once it fills up, nothing is left
+	site.SetTimes("south", dayISO, "9 Holes", []fakesite.TeeTime{{Time: "06:07", Layout: "Back 9 Morning", Spots: 0}})
+	site.SetTimes("south", dayISO, "18 holes", nil)
+	site.SetTimes("3000000", dayISO, "18 Holes", nil)
+	noCache = true
+	code, results, _ = runJSONSearch(t)
+	assert.Equal(t, exitNone, code)
+	assert.Empty(t, results)
+}
+
+func TestEndToEndDateRange(t *testing.T) {
+	from := time.Now().AddDate(0, 0, 1)
+	lastDay := from.AddDate(0, 0, 7).Format("2006-01-02")
+
+	withFakeSite(t,
+		fakeCourse{"North Golf Club", fakesite.Course{
+			Platform: fakesite.MiClub,
+			ID:       "3000000",
+			Times: map[string]map[string][]fakesite.TeeTime{
+				from.Format("2006-01-02"): {"18 Holes": {{Time: "08:00", Layout: "Main", Spots: 4}}},
+				lastDay:                   {"Twilight Special": {{Time: "17:10", Layout: "Back 9", Spots: 3}}},
+			},
+		}},
+		fakeCourse{"South Golf Course", fakesite.Course{
+			Platform: fakesite.Quick18,
+			ID:       "south",
+			Times: map[string]map[string][]fakesite.TeeTime{
+				from.AddDate(0, 0, 3).Format("2006-01-02"): {"9 holes": {{Time: "12:00", Layout: "Front 9", Spots: 1}}},
+			},
+		}},
+	)
+	fromDate = from.Format("02-01-2006")
+	searchDays = 8
+
+	code, results, errOut := runJSONSearch(t)
+	assert.Equal(t, exitFound, code)
+	assert.Empty(t, errOut)
+
+	require.Len(t, results, 3)
+	assert.Equal(t, from.Format("2006-01-02"), results[0].Date)
+	assert.Equal(t, "South Golf Course", results[1].Course)
+	assert.Equal(t, "9 Holes", results[1].Game)
+	assert.Equal(t, lastDay, results[2].Date, "the last day is past the first calendar page")
+	assert.Equal(t, "Twilight Special", results[2].Game)
+}
+
+func TestEndToEndFailures(t *testing.T) {
+	day := time.Now().AddDate(0, 0, 2)
+	dayISO := day.Format("2006-01-02")
+
+	withFakeSite(t,
+		fakeCourse{"North Golf Club", fakesite.Course{
+			Platform: fakesite.MiClub,
+			ID:       "3000000",
+			Times:    map[string]map[string][]fakesite.TeeTime{dayISO: {"18 Holes": {{Time: "07:00", Layout: "Main", Spots: 4}}}},
+		}},
+		fakeCourse{"Down Golf Club", fakesite.Course{Platform: fakesite.MiClub, ID: "3000001", Status: http.StatusServiceUnavailable}},
+		fakeCourse{"Blocked Golf Club", fakesite.Course{Platform: fakesite.Quick18, ID: "blocked", Status: http.StatusForbidden}},
+		fakeCourse{"Slow Golf Club", fakesite.Course{Platform: fakesite.Quick18, ID: "slow", Delay: time.Minute}},
+	)
+	specifiedDate = day.Format("02-01-2006")
+	courseTimeout = 200 * time.Millisecond
+
+	code, results, errOut := runJSONSearch(t)
+	assert.Equal(t, exitFound, code, "the courses that worked still count")
+	require.Len(t, results, 1)
+	assert.Equal(t, "North Golf Club", results[0].Course)
+
+	assert.Contains(t, errOut, "3 course(s) couldn't be searched")
+	assert.Contains(t, errOut, "Down Golf Club     booking site error")
+	assert.Contains(t, errOut, "503 Service Unavailable")
+	assert.Contains(t, errOut, "Blocked Golf Club  blocked by the booking site: 403 Forbidden")
+	assert.Contains(t, errOut, "Slow Golf Club     timed out after 200ms")
+}
+
+func TestEndToEndWatch(t *testing.T) {
+	day := time.Now().AddDate(0, 0, 2)
+	dayISO := day.Format("2006-01-02")
+
+	site := withFakeSite(t,
+		fakeCourse{"North Golf Club", fakesite.Course{
+			Platform: fakesite.MiClub,
+			ID:       "3000000",
+			Times:    map[string]map[string][]fakesite.TeeTime{dayISO: {"18 Holes": {{Time: "07:03", Layout: "Main", Spots: 2}}}},
+		}},
+	)
+	specifiedDate = day.Format("02-01-2006")
+	specifiedSpots = 4
+
+	origInterval, origSleep, origKeep := watchInterval, watchSleep, keepWatching
+	defer func() { watchInterval, watchSleep, keepWatching = origInterval, origSleep, origKeep }()
+	watchInterval = 5 * time.Minute
+	keepWatching = false
+
+	// Someone cancels a booking between the first and second check
+	watchSleep = func(context.Context, time.Duration) {
+		site.SetTimes("3000000", dayISO, "18 Holes", []fakesite.TeeTime{
+			{Time: "07:03", Layout: "Main", Spots: 2},
+			{Time: "07:11", Layout: "Main", Spots: 4},
+		})
+	}
+
+	var out, errOut bytes.Buffer
+	assert.Equal(t, exitFound, runWatch(context.Background(), &out, &errOut))
+	assert.Contains(t, errOut.String(), "Check 1: nothing new")
+	assert.Contains(t, errOut.String(), "Check 2: 1 new tee time(s)")
+
+	var got []searchResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	require.Len(t, got, 1)
+	assert.Equal(t, "07:11", got[0].Time)
+}
+
+func TestEndToEndScrapeDays(t *testing.T) {
+	day := time.Now().AddDate(0, 0, 2)
+	dayISO := day.Format("2006-01-02")
+
+	withFakeSite(t,
+		fakeCourse{"North Golf Club", fakesite.Course{
+			Platform: fakesite.MiClub,
+			ID:       "3000000",
+			Times: map[string]map[string][]fakesite.TeeTime{dayISO: {
+				"18 Holes":         {{Time: "07:00", Layout: "Main", Spots: 4}},
+				"Twilight Special": {{Time: "17:00", Layout: "Back 9", Spots: 2}},
+			}},
+		}},
+		fakeCourse{"South Golf Course", fakesite.Course{
+			Platform: fakesite.Quick18,
+			ID:       "south",
+			Times:    map[string]map[string][]fakesite.TeeTime{dayISO: {"18 holes": {{Time: "09:00", Layout: "Full", Spots: 3}}}},
+		}},
+	)
+
+	// The interactive search starts the same way, before any prompts
+	courses, err := loadCourses()
+	require.NoError(t, err)
+	f, err := newFetcher()
+	require.NoError(t, err)
+
+	days, failures := scrapeDays(context.Background(), f, courses, []time.Time{day})
+	assert.Empty(t, failures)
+	require.Len(t, days, 1)
+	assert.Equal(t, []string{"18 Holes"}, uniqueNames(days[0].standardGames))
+	assert.Equal(t, []string{"Twilight Special"}, uniqueNames(days[0].promoGames))
+	assert.Len(t, days[0].gameToTimeslotURLs["18 Holes"], 2, "both courses have 18 holes")
+
+	days, failures = preScrapeDays(context.Background(), f, days, 8*60, 10*60, 0, courses)
+	assert.Empty(t, failures)
+	require.Len(t, days, 1)
+	assert.Empty(t, days[0].promoGames, "the twilight game is outside the time filter")
+	assert.Equal(t, []string{"South Golf Course"}, keys(days[0].gameToTimeslotURLs["18 Holes"]), "North has nothing between 8 and 10")
+}
+
+// Helper function to drop the fees and booking link from a result
+func withoutExtras(r searchResult) searchResult {
+	r.Fees, r.BookingURL = nil, ""
+	return r
+}
+
+// Helper function to list a map's keys
+func keys[V any](m map[string]V) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+)
+
+// courseTimeoutError is the failure recorded for a course that took longer
+// than --timeout
+type courseTimeoutError struct {
+	after time.Duration
+}
+
+func (e courseTimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s", e.after)
+}
+
+// printFailures ends a run with a summary of the courses that couldn't be
+// searched and why, in name order. Nothing is printed if they all worked.
+func printFailures(w io.Writer, failures map[string]error) {
+	if len(failures) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(failures))
+	for name := range failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "%d course(s) couldn't be searched:\n", len(names))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(tw, "  %s\t%s\n", name, failureReason(failures[name]))
+	}
+	tw.Flush()
+}
+
+// failureReason explains a scrape error in a few words, with the detail
+// after a colon
+func failureReason(err error) string {
+	var (
+		timeout courseTimeoutError
+		blocked *fetch.BlockedError
+		status  *fetch.StatusError
+		network *fetch.NetworkError
+		page    *fetch.PageError
+		offline *fetch.OfflineError
+	)
+	switch {
+	case errors.As(err, &timeout):
+		return fmt.Sprintf("timed out after %s (try a longer --timeout)", timeout.after)
+	case errors.As(err, &blocked):
+		return fmt.Sprintf("blocked by the booking site: %s", blocked.Reason)
+	case errors.As(err, &status):
+		return fmt.Sprintf("booking site error: %s returned %s", status.URL, status.Status)
+	case errors.As(err, &network):
+		return fmt.Sprintf("couldn't reach the booking site: %v", network.Err)
+	case errors.As(err, &offline):
+		return "nothing saved for this course yet, search once without --offline"
+	case errors.As(err, &page) && len(page.Missing) > 0:
+		return fmt.Sprintf("the booking page's layout has changed, no match for %s (%s)", strings.Join(page.Missing, ", "), page.URL)
+	case errors.As(err, &page):
+		return fmt.Sprintf("page not recognised, the site may have changed: %s (%s)", page.Reason, page.URL)
+	}
+	return err.Error()
+}
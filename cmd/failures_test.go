@@ -0,0 +1,54 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintFailures(t *testing.T) {
+	var buf bytes.Buffer
+	printFailures(&buf, map[string]error{
+		"Slow":    courseTimeoutError{after: 30 * time.Second},
+		"Broken":  errors.New("unknown website type 'golfnow'"),
+		"Blocked": &fetch.BlockedError{URL: "https://b.test", Reason: "captcha or bot check page"},
+	})
+
+	assert.Equal(t, "3 course(s) couldn't be searched:\n"+
+		"  Blocked  blocked by the booking site: captcha or bot check page\n"+
+		"  Broken   unknown website type 'golfnow'\n"+
+		"  Slow     timed out after 30s (try a longer --timeout)\n", buf.String())
+
+	buf.Reset()
+	printFailures(&buf, nil)
+	assert.Empty(t, buf.String(), "nothing to report when every course worked")
+}
+
+func TestFailureReason(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"HTTP status", &fetch.StatusError{URL: "https://a.test/cal", StatusCode: 500, Status: "500 Internal Server Error"}, "booking site error: https://a.test/cal returned 500 Internal Server Error"},
+		{"Network", &fetch.NetworkError{URL: "https://a.test", Err: errors.New("connection refused")}, "couldn't reach the booking site: connection refused"},
+		{"Layout changed", &fetch.PageError{URL: "https://a.test/cal", Reason: "layout changed", Missing: []string{"div.feeGroupRow", "div.row-time"}}, "the booking page's layout has changed, no match for div.feeGroupRow, div.row-time (https://a.test/cal)"},
+		{"Page", &fetch.PageError{URL: "https://a.test/cal", Reason: "no fee group rows on the calendar"}, "page not recognised, the site may have changed: no fee group rows on the calendar (https://a.test/cal)"},
+		{"Other", errors.New("boom"), "boom"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, failureReason(c.err))
+		})
+	}
+}
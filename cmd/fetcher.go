@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+)
+
+// Cached pages older than this are deleted, they're no use even offline
+const cacheMaxAge = 7 * 24 * time.Hour
+
+var (
+	noCache     bool
+	offlineMode bool
+	cacheTTL    time.Duration
+	recordDir   string
+	replayDir   string
+
+	// pause between requests to the same site, tests against a local fake
+	// site turn it off
+	fetchDelay = fetch.DefaultDelay
+)
+
+// Helper function to get the page cache directory, next to the config file
+func cacheDir() string {
+	return filepath.Join(filepath.Dir(configPath), "cache")
+}
+
+// newFetcher makes the fetcher for one search, with the cache and
+// --demo/--record/--replay flags applied. Recording and replaying skip the cache so
+// every page really comes from the site or the recording.
+func newFetcher() (*fetch.Fetcher, error) {
+	f := fetch.New()
+	f.Delay = fetchDelay
+
+	switch {
+	case demoMode:
+		f.Client.Transport = demoTransport{}
+		f.Delay = 0
+		return f, nil
+
+	case replayDir != "":
+		replayer, err := fetch.NewReplayer(replayDir)
+		if err != nil {
+			return nil, err
+		}
+		f.Client.Transport = replayer
+		f.Delay = 0 // there's no site to be polite to
+		return f, nil
+
+	case recordDir != "":
+		recorder, err := fetch.NewRecorder(recordDir, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start recording: %v", err)
+		}
+		f.Client.Transport = recorder
+		return f, nil
+
+	case noCache:
+		return f, nil
+	}
+
+	f.Cache = fetch.NewCache(cacheDir(), cacheTTL)
+	f.Offline = offlineMode
+	if err := f.Cache.Prune(cacheMaxAge); err != nil {
+		debugPrintf("Failed to prune cache: %v\n", err)
+	}
+	return f, nil
+}
+
+// offlineNotice says how old the availability shown offline is. It is empty
+// when not offline or nothing came from the cache.
+func offlineNotice(f *fetch.Fetcher, now time.Time) string {
+	since := f.CachedSince()
+	if !f.Offline || since.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("Offline: showing availability saved %s (%s), it may have changed since.",
+		formatAge(now.Sub(since)), since.Format("15:04 02-01-2006"))
+}
+
+// Helper function to describe how long ago something was, roughly
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute") + " ago"
+	case d < 48*time.Hour:
+		return plural(int(d/time.Hour), "hour") + " ago"
+	}
+	return plural(int(d/(24*time.Hour)), "day") + " ago"
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
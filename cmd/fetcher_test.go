@@ -0,0 +1,87 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAge(t *testing.T) {
+	assert.Equal(t, "just now", formatAge(20*time.Second))
+	assert.Equal(t, "1 minute ago", formatAge(time.Minute))
+	assert.Equal(t, "45 minutes ago", formatAge(45*time.Minute))
+	assert.Equal(t, "3 hours ago", formatAge(3*time.Hour+10*time.Minute))
+	assert.Equal(t, "2 days ago", formatAge(50*time.Hour))
+}
+
+func TestNewFetcher(t *testing.T) {
+	origPath, origNoCache, origOffline, origTTL := configPath, noCache, offlineMode, cacheTTL
+	defer func() { configPath, noCache, offlineMode, cacheTTL = origPath, origNoCache, origOffline, origTTL }()
+	configPath = "/home/golfer/.config/TeeTimeFinder/config.txt"
+	cacheTTL = 5 * time.Minute
+
+	noCache, offlineMode = false, true
+	f, err := newFetcher()
+	require.NoError(t, err)
+	if assert.NotNil(t, f.Cache) {
+		assert.Equal(t, "/home/golfer/.config/TeeTimeFinder/cache", f.Cache.Dir, "the cache lives next to the config")
+		assert.Equal(t, 5*time.Minute, f.Cache.TTL)
+	}
+	assert.True(t, f.Offline)
+
+	noCache, offlineMode = true, false
+	f, err = newFetcher()
+	require.NoError(t, err)
+	assert.Nil(t, f.Cache)
+	assert.False(t, f.Offline)
+}
+
+func TestNewFetcherRecordReplay(t *testing.T) {
+	origRecord, origReplay := recordDir, replayDir
+	defer func() { recordDir, replayDir = origRecord, origReplay }()
+	dir := t.TempDir()
+
+	recordDir, replayDir = dir, ""
+	f, err := newFetcher()
+	require.NoError(t, err)
+	assert.IsType(t, &fetch.Recorder{}, f.Client.Transport)
+	assert.Nil(t, f.Cache, "a recording should come from the site, not the cache")
+
+	recordDir, replayDir = "", t.TempDir()
+	_, err = newFetcher()
+	assert.Error(t, err, "there's nothing to replay yet")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, fetch.ManifestFile), []byte(`{"responses": []}`), 0o644))
+	recordDir, replayDir = "", dir
+	f, err = newFetcher()
+	require.NoError(t, err)
+	assert.IsType(t, &fetch.Replayer{}, f.Client.Transport)
+	assert.Nil(t, f.Cache)
+}
+
+func TestOfflineNotice(t *testing.T) {
+	origPath, origNoCache, origOffline := configPath, noCache, offlineMode
+	defer func() { configPath, noCache, offlineMode = origPath, origNoCache, origOffline }()
+	configPath = t.TempDir() + "/config.txt"
+	noCache = false
+
+	offlineMode = true
+	f, err := newFetcher()
+	require.NoError(t, err)
+	assert.Empty(t, offlineNotice(f, time.Now()), "nothing to say until a cached page is used")
+
+	offlineMode = false
+	f, err = newFetcher()
+	require.NoError(t, err)
+	assert.Empty(t, offlineNotice(f, time.Now()), "only offline searches are labelled")
+}
@@ -19,12 +19,8 @@ func setupLogging() (*os.File, error) {
 		return nil, nil
 	}
 
-	// ~/.config/TeeTimeFinder/debug.log  (mkdir -p if necessary)
-	cfgDir, err := os.UserConfigDir()
-	if err != nil {
-		return nil, err
-	}
-	logDir := filepath.Join(cfgDir, "TeeTimeFinder")
+	// debug.log goes next to the config file  (mkdir -p if necessary)
+	logDir := filepath.Dir(configPath)
 	if err := os.MkdirAll(logDir, 0o700); err != nil {
 		return nil, err
 	}
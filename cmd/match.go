@@ -0,0 +1,132 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// matchCourse finds the course meant by a name typed with -c or in the
+// start form. An exact name or alias (ignoring case) wins, then any course
+// whose name or alias contains what was typed, then ones that are a typo or
+// two away. If more than one course is left the name is empty and they're
+// returned as suggestions instead.
+func matchCourse(all map[string]CourseConfig, typed string) (string, []string) {
+	if name, ok := findCourseInsensitive(all, typed); ok {
+		return name, nil
+	}
+
+	words := matchWords(typed)
+	if len(words) == 0 {
+		return "", nil
+	}
+	want := strings.Join(words, " ")
+
+	contains := make(map[string]bool)
+	typos := make(map[string]bool)
+	for name, cfg := range all {
+		for _, candidate := range append([]string{name}, cfg.Aliases...) {
+			have := matchWords(candidate)
+			if strings.Contains(strings.Join(have, " "), want) {
+				contains[name] = true
+			} else if closeEnough(have, words) {
+				typos[name] = true
+			}
+		}
+	}
+
+	found := contains
+	if len(found) == 0 {
+		found = typos
+	}
+	if len(found) == 1 {
+		for name := range found {
+			return name, nil
+		}
+	}
+
+	var suggestions []string
+	for name := range found {
+		suggestions = append(suggestions, name)
+	}
+	sort.Strings(suggestions)
+	return "", suggestions
+}
+
+// Helper function to explain why a typed course name didn't match, with
+// the courses it could have meant
+func courseMatchError(typed string, suggestions []string) error {
+	if len(suggestions) == 0 {
+		return fmt.Errorf("course '%s' does not exist in config", typed)
+	}
+	return fmt.Errorf("course '%s' matches more than one course, did you mean %s", typed, orList(suggestions))
+}
+
+// Helper function to list names as "A", "B" or "C"
+func orList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = fmt.Sprintf("%q", n)
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+// Helper function to lower case a name and split it into words, dropping
+// punctuation so "St. Andrews" and "st andrews" are the same
+func matchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Helper function to check if the typed words are a small typo away from
+// the same number of words in a row somewhere in a name. Short names need
+// to be spot on, longer ones can be off by a letter for every four.
+func closeEnough(name, typed []string) bool {
+	want := strings.Join(typed, " ")
+	if len(want) < 4 {
+		return false
+	}
+	allowed := len(want) / 4
+
+	for start := 0; start+len(typed) <= len(name); start++ {
+		have := strings.Join(name[start:start+len(typed)], " ")
+		if editDistance(have, want) <= allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper function to count the letters that need adding, removing or
+// changing to turn a into b (Levenshtein distance)
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
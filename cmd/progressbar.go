@@ -6,6 +6,7 @@
 package cmd
 
 import (
+	"context"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/progress"
@@ -23,12 +24,14 @@ type pbModel struct {
 	done          int
 	logs          []string
 	width, height int
+	cancel        context.CancelFunc // stops the scrape on ctrl+c
 }
 
-func newPB(total int) pbModel {
+func newPB(total int, cancel context.CancelFunc) pbModel {
 	return pbModel{
-		bar:   progress.New(progress.WithDefaultGradient()),
-		total: total,
+		bar:    progress.New(progress.WithDefaultGradient()),
+		total:  total,
+		cancel: cancel,
 	}
 }
 
@@ -47,6 +50,14 @@ func (m pbModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		return m, cmd
 
+	case tea.KeyMsg:
+		// the alt screen swallows ctrl+c, so cancel the scrape ourselves
+		if v.String() == "ctrl+c" && m.cancel != nil {
+			m.cancel()
+			m.logs = append(m.logs, "Cancelling...")
+		}
+		return m, nil
+
 	case logMsg:
 		m.logs = append(m.logs, strings.TrimRight(string(v), "\n"))
 		return m, nil
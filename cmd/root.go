@@ -7,19 +7,27 @@ package cmd
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/Ay1tsMe/TeeTimeFinder/pkg/miclub"
-	"github.com/Ay1tsMe/TeeTimeFinder/pkg/quick18"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/config"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/provider"
 	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
 
+	// Booking platforms register themselves with the provider package
+	_ "github.com/Ay1tsMe/TeeTimeFinder/pkg/miclub"
+	_ "github.com/Ay1tsMe/TeeTimeFinder/pkg/quick18"
+
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
@@ -29,20 +37,41 @@ import (
 type CourseConfig struct {
 	URL         string
 	WebsiteType string
+	Aliases     []string
+	Tags        []string
 	Blacklisted bool
 }
 
+// Helper function to check if a course is tagged with tag, ignoring case
+func (c CourseConfig) hasTag(tag string) bool {
+	for _, t := range c.Tags {
+		if strings.EqualFold(strings.TrimSpace(t), tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // bubbletea model
 type startFormModel struct {
 	focus     int
 	done      bool
 	err       error
 	in        []textinput.Model // 0=course choice, 1=date, 2=time, 3=spots
-	locked    []bool
-	courses   []string
-	blacklist map[string]bool
+	locked    []bool            // one per field, the group picker is last
+	all       map[string]CourseConfig
+	courses   []string        // sorted names from all
+	tags      []string        // every tag in the config
+	picked    map[string]bool // tags picked in the group picker
+	tagCursor int
 }
 
+// The group picker isn't a text input so it goes after them in locked, but
+// it's shown (and tabbed to) straight after the courses
+const groupsField = 4
+
+var startFieldOrder = []int{0, groupsField, 1, 2, 3}
+
 // For releases
 var (
 	appName               = "TeeTimeFinder"
@@ -61,15 +90,31 @@ var allowedStandardModifiers = map[string]bool{
 
 var specifiedTime string
 var specifiedDate string
+var fromDate string
+var toDate string
+var searchDays int
 var specifiedSpots int
+var configDefaults config.Defaults // from the config, for flags left blank
 var globalSelectedDate time.Time
 var verboseMode bool
 var courseList []string
+var groupList []string
 var choice string
 var progressProgram *tea.Program
 
-// Pre-scraped data structure to hold all times if a time filter is used
-var preScrapedTimes map[string]map[string]map[string][]shared.TeeTimeSlot
+// Longest date range we'll search in one go, to go easy on the booking sites
+const maxSearchDays = 14
+
+// dayResult holds everything found for one date of the search
+type dayResult struct {
+	date               time.Time
+	standardGames      []string
+	promoGames         []string
+	gameToTimeslotURLs map[string]map[string]string
+
+	// Pre-scraped data structure to hold all times if a time filter is used
+	preScraped map[string]map[string]map[string][]shared.TeeTimeSlot
+}
 
 var parenthesisRegex = regexp.MustCompile(`\(.+?\)`)
 var nineHoleRegex = regexp.MustCompile(`\b9\s*hole(s)?\b`)
@@ -84,15 +129,21 @@ var rootCmd = &cobra.Command{
 	Long:  `TeeTimeFinder allows you to find and book tee times for MiClub golf courses.`,
 	Args:  cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		runScraper(args)
+		runScraper(cmd.Context(), args)
 	},
 }
 
 func Execute() {
-	err := rootCmd.Execute()
+	cmd, err := rootCmd.ExecuteC()
 	if err != nil {
+		if cmd == searchCmd || cmd == watchCmd {
+			os.Exit(exitErrors)
+		}
 		os.Exit(1)
 	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
 }
 
 func init() {
@@ -100,16 +151,42 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&specifiedTime, "time", "t", "", "Filter times within 1 hour before and after the specified time (e.g., 12:00)")
 	rootCmd.PersistentFlags().StringVarP(&specifiedDate, "date", "d", "", "Specify the date for the tee time search (format: DD-MM-YYYY)")
 	rootCmd.PersistentFlags().IntVarP(&specifiedSpots, "spots", "s", 0, "Filter timeslots based on available player spots (1-4)")
+	rootCmd.PersistentFlags().StringVar(&fromDate, "from", "", "Search a range of dates starting on this date (format: DD-MM-YYYY)")
+	rootCmd.PersistentFlags().StringVar(&toDate, "to", "", "Last date of the range to search (format: DD-MM-YYYY)")
+	rootCmd.PersistentFlags().IntVar(&searchDays, "days", 0, fmt.Sprintf("Search this many days starting from the selected date (max %d)", maxSearchDays))
 	rootCmd.PersistentFlags().StringArrayVarP(&courseList, "courses", "c", nil, "Specify particular courses to search")
+	rootCmd.PersistentFlags().StringArrayVarP(&groupList, "group", "g", nil, "Search the courses tagged with this group (e.g. south)")
+	rootCmd.PersistentFlags().IntVarP(&scrapeWorkers, "workers", "w", defaultWorkers, "Number of courses to scrape at the same time")
+	rootCmd.PersistentFlags().IntVar(&perHostWorkers, "per-host", defaultPerHostWorkers, "Maximum courses to scrape at the same time from one booking site")
+	rootCmd.PersistentFlags().DurationVar(&courseTimeout, "timeout", defaultCourseTimeout, "Give up on a course that takes longer than this to scrape (0 for no limit)")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", fetch.DefaultCacheTTL, "Reuse pages fetched within this long instead of fetching them again")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Don't read or save cached pages")
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", false, "Only show the availability saved by earlier searches, without going online")
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "Save every page fetched into this directory, for replaying later")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "Search using only the pages saved by --record in this directory")
+	rootCmd.PersistentFlags().BoolVar(&demoMode, "demo", false, "Try TeeTimeFinder on built-in sample courses, no config needed")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", fmt.Sprintf("Use this config file instead of the default (or set $%s)", configEnv))
+	rootCmd.MarkFlagsMutuallyExclusive("no-cache", "offline")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay", "offline", "demo")
 	rootCmd.PersistentFlags().BoolVarP(&verboseMode, "verbose", "v", false, "Enable verbose debug output (Creates debug.log file found in your config directory)")
 
 	// Initalise logging for -verbose flag
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		applyConfigFlag()
+
 		f, err := setupLogging()
 		if err != nil {
 			return err
 		}
 		logFile = f
+
+		if demoMode {
+			return nil
+		}
+		if err := migrateConfig(); err != nil {
+			return err
+		}
+		applyConfigDefaults(cmd)
 		return nil
 	}
 
@@ -119,24 +196,13 @@ func init() {
 		}
 	}
 
-	// Register the completion function here
-	rootCmd.RegisterFlagCompletionFunc("courses", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		// Load courses from config
-		courses, err := loadCourses()
-		if err != nil {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
-
-		// Filter courses
-		var completions []string
-		for courseName := range courses {
-			if strings.HasPrefix(strings.ToLower(courseName), strings.ToLower(toComplete)) {
-				completions = append(completions, courseName)
-			}
-		}
-
-		return completions, cobra.ShellCompDirectiveNoFileComp
-	})
+	// Register the completion functions here, they're in completion.go
+	rootCmd.RegisterFlagCompletionFunc("courses", completeCourses)
+	rootCmd.RegisterFlagCompletionFunc("group", completeGroups)
+	rootCmd.RegisterFlagCompletionFunc("date", completeDates)
+	rootCmd.RegisterFlagCompletionFunc("from", completeDates)
+	rootCmd.RegisterFlagCompletionFunc("to", completeDates)
+	rootCmd.RegisterFlagCompletionFunc("time", completeTimes)
 }
 
 // Debug print functions that only print if verboseMode is true
@@ -153,7 +219,7 @@ func debugPrintf(format string, a ...interface{}) {
 }
 
 // Function to run the scraper
-func runScraper(args []string) {
+func runScraper(ctx context.Context, args []string) {
 	// bubbletea logic
 	courses, err := loadCourses()
 	if err != nil {
@@ -168,79 +234,51 @@ func runScraper(args []string) {
 	}
 
 	// feed answers into the existing flag-backed vars
-	if ans.date != "" {
+	if ans.date != "" && fromDate == "" {
 		specifiedDate = ans.date
 	}
-	if ans.time != "" {
-		specifiedTime = ans.time
-	}
-	if ans.spots != "" {
-		if v, _ := strconv.Atoi(ans.spots); v > 0 {
-			specifiedSpots = v
-		}
-	}
+	applyStartAnswers(ans)
 	choice = strings.TrimSpace(strings.ToLower(ans.courseChoice)) // course names typed in the form
 
 	debugPrintf("Loaded courses: %+v\n", courses)
 
-	var filtered map[string]CourseConfig
-
-	// user passed one or more -c flags
-	if len(courseList) > 0 {
-		filtered = make(map[string]CourseConfig)
-		for _, n := range courseList {
-			n = strings.TrimSpace(n)
-			canon, ok := findCourseInsensitive(courses, n)
-			if !ok {
-				fmt.Printf("Error: Course '%s' does not exist in config.\n", n)
-				return
-			}
-			filtered[canon] = courses[canon]
-		}
-		courses = filtered
-
-		// otherwise use whatever they typed in the first text input
-	} else if choice != "" {
-		filtered = make(map[string]CourseConfig)
-		for _, raw := range strings.Split(choice, ",") {
-			n := strings.TrimSpace(raw)
-			if n == "" {
-				continue
-			}
-			canon, ok := findCourseInsensitive(courses, n)
-			if !ok {
-				fmt.Printf("Error: Course '%s' does not exist in config.\n", n)
-				return
-			}
-			filtered[canon] = courses[canon]
-		}
-		if len(filtered) > 0 {
-			courses = filtered
-		}
+	// user passed one or more -c flags, otherwise use whatever they typed in
+	// the first text input
+	names := courseList
+	if len(names) == 0 && choice != "" {
+		names = strings.Split(choice, ",")
 	}
-
-	// remove black-listed courses unless user explicitly requested them
-	for n, cfg := range courses {
-		if cfg.Blacklisted {
-			debugPrintf("Skipping blacklisted course: %s\n", n)
-			delete(courses, n)
-		}
+	groups := groupList
+	if len(groups) == 0 {
+		groups = ans.groups
+	}
+	courses, err = selectCourses(courses, names, groups)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
 	}
 
+	// ctrl+c in the progress bar or spinner cancels whatever is still loading
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// start animated progress-bar (one tick per course scraped)
 	totalCourses := len(courses)
-	pbar := tea.NewProgram(newPB(totalCourses), tea.WithAltScreen())
+	pbar := tea.NewProgram(newPB(totalCourses, cancel), tea.WithAltScreen())
 	go func() { _ = pbar.Start() }()
 	progressProgram = pbar
 
-	selectedDate, err := handleDateInput()
+	dates, err := handleDateRange()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	debugPrintf("Selected date: %s\n", selectedDate.Format("2006-01-02"))
+	debugPrintf("Selected dates: %s to %s\n", dates[0].Format("2006-01-02"), dates[len(dates)-1].Format("2006-01-02"))
 
-	globalSelectedDate = selectedDate
+	// the past-time check only makes sense for a single day
+	if len(dates) == 1 {
+		globalSelectedDate = dates[0]
+	}
 
 	filterStartMinutes, filterEndMinutes, err := handleTimeInput()
 	if err != nil {
@@ -256,7 +294,15 @@ func runScraper(args []string) {
 	}
 	debugPrintf("Spots filter used: %v, spots required: %d\n", spotsFilterUsed, specifiedSpots)
 
-	standardGames, promoGames, gameToTimeslotURLs := scrapeCourseData(courses, selectedDate)
+	// one fetcher for the whole search so no page is downloaded twice
+	f, err := newFetcher()
+	if err != nil {
+		pbar.Quit()
+		pbar.Wait()
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	days, failures := scrapeDays(ctx, f, courses, dates)
 
 	// mark progress bar 100 % and close it
 	pbar.Send(pbMsg(totalCourses))
@@ -264,11 +310,29 @@ func runScraper(args []string) {
 	fmt.Print("\r\033[K\n")
 	fmt.Println()
 
-	debugPrintf("Standard Games: %v\n", standardGames)
-	debugPrintf("Promo Games: %v\n", promoGames)
+	if ctx.Err() != nil {
+		fmt.Println("Search cancelled.")
+		return
+	}
 
-	if len(standardGames) == 0 && len(promoGames) == 0 {
-		fmt.Println("No available games found on the selected date.")
+	for _, day := range days {
+		debugPrintf("%s Standard Games: %v\n", day.date.Format("2006-01-02"), day.standardGames)
+		debugPrintf("%s Promo Games: %v\n", day.date.Format("2006-01-02"), day.promoGames)
+	}
+
+	if len(days) == 0 {
+		printFailures(os.Stdout, failures)
+		if notice := offlineNotice(f, time.Now()); notice != "" {
+			fmt.Println(notice)
+		}
+		if demoMode {
+			fmt.Println(demoNotice)
+		}
+		if len(dates) > 1 {
+			fmt.Println("No available games found on the selected dates.")
+		} else {
+			fmt.Println("No available games found on the selected date.")
+		}
 		return
 	}
 
@@ -276,39 +340,74 @@ func runScraper(args []string) {
 	timeFilterUsed := (filterStartMinutes != 0 || filterEndMinutes != 0) || spotsFilterUsed
 
 	if timeFilterUsed || spotsFilterUsed {
-		runWithSpinner("Searching all courses for specified criteria... (this can take a while)",
+		runWithSpinner("Searching all courses for specified criteria... (this can take a while)", cancel,
 			func() {
 				debugPrintln("Pre-scraping all times due to filters.")
-				preScrapedTimes = preScrapeAllTimes(
-					gameToTimeslotURLs,
-					filterStartMinutes, filterEndMinutes,
-					specifiedSpots,
-					courses,
-				)
+				var timeFailures map[string]error
+				days, timeFailures = preScrapeDays(ctx, f, days, filterStartMinutes, filterEndMinutes, specifiedSpots, courses)
+				for course, err := range timeFailures {
+					failures[course] = err
+				}
 			},
 		)
+		if ctx.Err() != nil {
+			fmt.Println("Search cancelled.")
+			return
+		}
+	}
 
-		debugPrintln("Filtering available games and courses after pre-scrape.")
-		standardGames, promoGames, gameToTimeslotURLs = filterAvailableGamesAndCourses(standardGames, promoGames, gameToTimeslotURLs, preScrapedTimes)
+	// every course has been searched now, so say which ones didn't work
+	printFailures(os.Stdout, failures)
+	if notice := offlineNotice(f, time.Now()); notice != "" {
+		fmt.Println(notice)
+	}
+	if demoMode {
+		fmt.Println(demoNotice)
+	}
 
-		debugPrintf("After filtering: StandardGames: %v, PromoGames: %v\n", standardGames, promoGames)
-		if len(standardGames) == 0 && len(promoGames) == 0 {
+	if timeFilterUsed || spotsFilterUsed {
+		if len(days) == 0 {
 			fmt.Println("No available games found for the specified time range.")
 			return
 		}
 	}
 
 	for {
-		selectedGame := promptGameSelection(standardGames, promoGames, gameToTimeslotURLs)
+		// with more than one date, pick which day to look at first
+		day := days[0]
+		if len(days) > 1 {
+			idx := promptDateSelection(days)
+			if idx < 0 {
+				fmt.Println("\nQuitting TeeTimeFinder. Goodbye!")
+				return
+			}
+			day = days[idx]
+		}
+
+		backToDates := browseDay(ctx, f, day, timeFilterUsed, spotsFilterUsed, filterStartMinutes, filterEndMinutes, courses)
+		if !backToDates {
+			return
+		}
+		if len(days) == 1 {
+			fmt.Println("\nQuitting TeeTimeFinder. Goodbye!")
+			return
+		}
+	}
+}
+
+// browseDay runs the game, course and tee time selection for one date. It
+// returns true if the user backed out of game selection without picking one.
+func browseDay(ctx context.Context, f *fetch.Fetcher, day dayResult, timeFilterUsed, spotsFilterUsed bool, filterStartMinutes, filterEndMinutes int, courses map[string]CourseConfig) bool {
+	for {
+		selectedGame := promptGameSelection(day.standardGames, day.promoGames, day.gameToTimeslotURLs)
 		debugPrintf("User selected game: %s\n", selectedGame)
 
 		if selectedGame == "" {
 			debugPrintln("No game selected, stopping.")
-			fmt.Println("\nQuitting TeeTimeFinder. Goodbye!")
-			break
+			return true
 		}
 
-		selectedCourse, timeslotURL := promptCourseSelection(gameToTimeslotURLs[selectedGame])
+		selectedCourse, timeslotURL := promptCourseSelection(day.gameToTimeslotURLs[selectedGame])
 		debugPrintf("User selected course: %s, URL: %s\n", selectedCourse, timeslotURL)
 
 		if selectedCourse == "" {
@@ -317,10 +416,11 @@ func runScraper(args []string) {
 		}
 
 		debugPrintf("Displaying times. timeFilterUsed: %v, spotsFilterUsed: %v\n", timeFilterUsed, spotsFilterUsed)
+		var chosenSlot *shared.TeeTimeSlot
 		if timeFilterUsed || spotsFilterUsed {
-			handleTimesDisplayPreScraped(preScrapedTimes[selectedGame][selectedCourse], filterStartMinutes, filterEndMinutes, specifiedSpots, selectedGame, selectedCourse, courses)
+			chosenSlot = handleTimesDisplayPreScraped(day.preScraped[selectedGame][selectedCourse])
 		} else {
-			handleTimesDisplay(timeslotURL, selectedGame, selectedCourse, filterStartMinutes, filterEndMinutes, specifiedSpots, courses)
+			chosenSlot = handleTimesDisplay(ctx, f, timeslotURL, selectedGame, selectedCourse, filterStartMinutes, filterEndMinutes, specifiedSpots, courses)
 		}
 
 		// Ask user if they want to book this game
@@ -328,7 +428,16 @@ func runScraper(args []string) {
 		bookingChoice := strings.ToLower(strings.TrimSpace(readInput()))
 
 		if bookingChoice == "yes" || bookingChoice == "y" {
-			fmt.Printf("Here is the URL for this game: %s\n", timeslotURL)
+			if chosenSlot != nil && chosenSlot.BookingURL != "" {
+				prettyTime := reSpaceAMPMRegex.ReplaceAllString(chosenSlot.Time, "$1 $2")
+				fmt.Printf("Here is the URL for your %s tee time: %s\n", prettyTime, chosenSlot.BookingURL)
+			} else {
+				bookingURL := timeslotURL
+				if p, ok := provider.Lookup(courses[selectedCourse].WebsiteType); ok {
+					bookingURL = p.BookingURL(timeslotURL)
+				}
+				fmt.Printf("Here is the URL for this game: %s\n", bookingURL)
+			}
 		} else {
 			fmt.Println("Skipping Booking.")
 		}
@@ -341,13 +450,121 @@ func runScraper(args []string) {
 			continue
 		}
 		fmt.Println("Enjoy your round. Goodbye!")
-		return
+		return false
+	}
+}
+
+// selectCourses narrows the config down to the named courses and the ones
+// tagged with any of the groups (all of them if neither is given). Blacklisted
+// courses are always dropped, even when named, and it's an error if that
+// leaves nothing to search.
+func selectCourses(courses map[string]CourseConfig, names, groups []string) (map[string]CourseConfig, error) {
+	filtered := make(map[string]CourseConfig)
+	for _, raw := range names {
+		n := strings.TrimSpace(raw)
+		if n == "" {
+			continue
+		}
+		canon, suggestions := matchCourse(courses, n)
+		if canon == "" {
+			return nil, courseMatchError(n, suggestions)
+		}
+		if !strings.EqualFold(canon, n) {
+			debugPrintf("Matched %q to %s\n", n, canon)
+		}
+		filtered[canon] = courses[canon]
+	}
+	for _, raw := range groups {
+		for _, group := range splitTags(raw) {
+			found := false
+			for n, cfg := range courses {
+				if cfg.hasTag(group) {
+					filtered[n] = cfg
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("no courses are tagged '%s' in config", group)
+			}
+		}
+	}
+	if len(filtered) == 0 {
+		for n, cfg := range courses {
+			filtered[n] = cfg
+		}
+	}
+
+	selected := len(filtered)
+	for n, cfg := range filtered {
+		if cfg.Blacklisted {
+			debugPrintf("Skipping blacklisted course: %s\n", n)
+			delete(filtered, n)
+		}
+	}
+	if selected > 0 && len(filtered) == 0 {
+		return nil, errors.New("every course selected is blacklisted")
+	}
+	return filtered, nil
+}
+
+// Helper function to split a comma separated list of tags, dropping blanks
+func splitTags(list string) []string {
+	var tags []string
+	for _, t := range strings.Split(list, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
 	}
+	return tags
+}
+
+// scrapeDays finds the games on each of the dates for every course. Only
+// dates with at least one game are returned, along with any courses that
+// couldn't be scraped (or timed out).
+func scrapeDays(ctx context.Context, f *fetch.Fetcher, courses map[string]CourseConfig, dates []time.Time) ([]dayResult, map[string]error) {
+	if len(dates) > 1 {
+		return scrapeCourseDataRange(ctx, f, courses, dates[0], dates[len(dates)-1])
+	}
+
+	standardGames, promoGames, gameToTimeslotURLs, failures := scrapeCourseData(ctx, f, courses, dates[0])
+	if len(standardGames) == 0 && len(promoGames) == 0 {
+		return nil, failures
+	}
+	return []dayResult{{
+		date:               dates[0],
+		standardGames:      standardGames,
+		promoGames:         promoGames,
+		gameToTimeslotURLs: gameToTimeslotURLs,
+	}}, failures
+}
+
+// preScrapeDays pre-scrapes every day's times with the filters applied and
+// drops the games, courses and days left without any times.
+func preScrapeDays(ctx context.Context, f *fetch.Fetcher, days []dayResult, filterStartMinutes, filterEndMinutes, spots int, courses map[string]CourseConfig) ([]dayResult, map[string]error) {
+	failures := make(map[string]error)
+	var remaining []dayResult
+	for _, day := range days {
+		var dayFailures map[string]error
+		day.preScraped, dayFailures = preScrapeAllTimes(ctx, f, day.gameToTimeslotURLs, filterStartMinutes, filterEndMinutes, spots, courses)
+		for course, err := range dayFailures {
+			failures[course] = err
+		}
+
+		debugPrintln("Filtering available games and courses after pre-scrape.")
+		day.standardGames, day.promoGames, day.gameToTimeslotURLs = filterAvailableGamesAndCourses(day.standardGames, day.promoGames, day.gameToTimeslotURLs, day.preScraped)
+		debugPrintf("After filtering %s: StandardGames: %v, PromoGames: %v\n", day.date.Format("2006-01-02"), day.standardGames, day.promoGames)
+
+		if len(day.standardGames) > 0 || len(day.promoGames) > 0 {
+			remaining = append(remaining, day)
+		}
+	}
+	return remaining, failures
 }
 
 // runWithSpinner shows a spinner with the given message while fn() runs.
-func runWithSpinner(msg string, fn func()) {
-	spinProg := tea.NewProgram(newSpinnerModel(msg), tea.WithAltScreen(), tea.WithOutput(os.Stdout))
+// Pressing ctrl+c calls cancel, which fn() is expected to notice.
+func runWithSpinner(msg string, cancel context.CancelFunc, fn func()) {
+	spinProg := tea.NewProgram(newSpinnerModel(msg, cancel), tea.WithAltScreen(), tea.WithOutput(os.Stdout))
 
 	go func() { _ = spinProg.Start() }()
 
@@ -359,6 +576,9 @@ func runWithSpinner(msg string, fn func()) {
 }
 
 func handleSpotsInput() (bool /*filterUsed*/, error) {
+	if specifiedSpots == 0 {
+		specifiedSpots = configDefaults.Spots
+	}
 	if specifiedSpots == 0 { // blank / default
 		return false, nil // no filter
 	}
@@ -369,47 +589,67 @@ func handleSpotsInput() (bool /*filterUsed*/, error) {
 }
 
 // Function to pre-scrape all times if filters are specified
-func preScrapeAllTimes(gameToTimeslotURLs map[string]map[string]string, filterStartMinutes, filterEndMinutes, spots int, courses map[string]CourseConfig) map[string]map[string]map[string][]shared.TeeTimeSlot {
+func preScrapeAllTimes(ctx context.Context, f *fetch.Fetcher, gameToTimeslotURLs map[string]map[string]string, filterStartMinutes, filterEndMinutes, spots int, courses map[string]CourseConfig) (map[string]map[string]map[string][]shared.TeeTimeSlot, map[string]error) {
 	preScraped := make(map[string]map[string]map[string][]shared.TeeTimeSlot)
+	failures := make(map[string]error)
+
+	// group the games by course so each course is one job for the workers
+	courseToGames := make(map[string]map[string]string)
 	for game, courseMap := range gameToTimeslotURLs {
 		debugPrintf("Pre-scrape: Checking game '%s'\n", game)
-		if preScraped[game] == nil {
-			preScraped[game] = make(map[string]map[string][]shared.TeeTimeSlot)
-		}
+		preScraped[game] = make(map[string]map[string][]shared.TeeTimeSlot)
 		for courseName, timeslotURL := range courseMap {
-			debugPrintf("Pre-scrape: Scraping times for course '%s', URL: %s\n", courseName, timeslotURL)
+			if courseToGames[courseName] == nil {
+				courseToGames[courseName] = make(map[string]string)
+			}
+			courseToGames[courseName][game] = timeslotURL
+		}
+	}
 
-			var availableTimes map[string][]shared.TeeTimeSlot
-			var err error
+	toScrape := make(map[string]CourseConfig, len(courseToGames))
+	for courseName := range courseToGames {
+		toScrape[courseName] = courses[courseName]
+	}
 
-			if strings.EqualFold(courses[courseName].WebsiteType, "miclub") {
-				availableTimes, err = miclub.ScrapeTimes(timeslotURL)
-			} else if strings.EqualFold(courses[courseName].WebsiteType, "quick18") {
-				qTimes, e := quick18.ScrapeTimes(timeslotURL)
-				err = e
+	scrapeCourses(ctx, toScrape,
+		func(ctx context.Context, courseName string, cfg CourseConfig) (map[string]map[string][]shared.TeeTimeSlot, error) {
+			p, ok := provider.Lookup(cfg.WebsiteType)
+			if !ok {
+				debugPrintf("Unknown website type '%s' for course '%s'\n", cfg.WebsiteType, courseName)
+				return nil, fmt.Errorf("unknown website type '%s'", cfg.WebsiteType)
+			}
+
+			gameTimes := make(map[string]map[string][]shared.TeeTimeSlot)
+			var firstErr error
+			for game, timeslotURL := range courseToGames[courseName] {
+				debugPrintf("Pre-scrape: Scraping times for course '%s', URL: %s\n", courseName, timeslotURL)
 
-				if err == nil {
-					filtered := make(map[string][]shared.TeeTimeSlot)
-					if colTimes, ok := qTimes[game]; ok {
-						filtered[game] = colTimes
+				availableTimes, err := p.ScrapeTimes(ctx, f, timeslotURL, game)
+				if err != nil {
+					debugPrintf("Error scraping times for %s at %s: %v\n", game, courseName, err)
+					if firstErr == nil {
+						firstErr = err
 					}
-					qTimes = filtered
+					continue
 				}
 
-				availableTimes = qTimes
+				filteredTimes := filterAndSortTimes(availableTimes, filterStartMinutes, filterEndMinutes, spots)
+				debugPrintf("Pre-scrape: '%s' at '%s' after filtering: %+v\n", game, courseName, filteredTimes)
+				gameTimes[game] = filteredTimes
 			}
-
+			return gameTimes, firstErr
+		},
+		func(courseName string, gameTimes map[string]map[string][]shared.TeeTimeSlot, err error) {
 			if err != nil {
-				debugPrintf("Error scraping times for %s at %s: %v\n", game, courseName, err)
-				continue
+				failures[courseName] = err
+			}
+			for game, layoutTimes := range gameTimes {
+				preScraped[game][courseName] = layoutTimes
 			}
+		},
+	)
 
-			filteredTimes := filterAndSortTimes(availableTimes, filterStartMinutes, filterEndMinutes, spots)
-			debugPrintf("Pre-scrape: '%s' at '%s' after filtering: %+v\n", game, courseName, filteredTimes)
-			preScraped[game][courseName] = filteredTimes
-		}
-	}
-	return preScraped
+	return preScraped, failures
 }
 
 func filterAvailableGamesAndCourses(standardGames, promoGames []string, gameToTimeslotURLs map[string]map[string]string, preScraped map[string]map[string]map[string][]shared.TeeTimeSlot) ([]string, []string, map[string]map[string]string) {
@@ -499,24 +739,14 @@ func filterAndSortTimes(availableTimes map[string][]shared.TeeTimeSlot, filterSt
 	return layoutTimes
 }
 
-func handleTimesDisplayPreScraped(layoutTimes map[string][]shared.TeeTimeSlot, filterStartMinutes, filterEndMinutes, spots int, selectedGame string, selectedCourse string, courses map[string]CourseConfig) {
-
+func handleTimesDisplayPreScraped(layoutTimes map[string][]shared.TeeTimeSlot) *shared.TeeTimeSlot {
 	debugPrintf("handleTimesDisplayPreScraped called with layouts: %v\n", layoutTimes)
 
-	// Filter out columns not matching the user's chosen selectedGame
-	if strings.EqualFold(courses[selectedCourse].WebsiteType, "quick18") {
-		filteredMap := make(map[string][]shared.TeeTimeSlot)
-		if timesForGame, ok := layoutTimes[selectedGame]; ok {
-			filteredMap[selectedGame] = timesForGame
-		}
-		layoutTimes = filteredMap
-	}
-
 	if len(layoutTimes) == 0 {
 		fmt.Println("No available times with the specified filters.")
-		return
+		return nil
 	}
-	displaySortedTimes(layoutTimes, sortLayoutsByEarliest(layoutTimes))
+	return displaySortedTimes(layoutTimes, sortLayoutsByEarliest(layoutTimes))
 }
 
 func sortLayoutsByEarliest(layoutTimes map[string][]shared.TeeTimeSlot) []string {
@@ -539,48 +769,44 @@ func sortLayoutsByEarliest(layoutTimes map[string][]shared.TeeTimeSlot) []string
 	return sortedLayouts
 }
 
-func handleTimesDisplay(timeslotURL, selectedGame, selectedCourse string, filterStartMinutes, filterEndMinutes, spots int, courses map[string]CourseConfig) {
+func handleTimesDisplay(ctx context.Context, f *fetch.Fetcher, timeslotURL, selectedGame, selectedCourse string, filterStartMinutes, filterEndMinutes, spots int, courses map[string]CourseConfig) *shared.TeeTimeSlot {
 	debugPrintf("handleTimesDisplay for %s at %s, URL: %s\n", selectedGame, selectedCourse, timeslotURL)
 
-	var availableTimes map[string][]shared.TeeTimeSlot
-	var err error
-
-	if strings.EqualFold(courses[selectedCourse].WebsiteType, "miclub") {
-		availableTimes, err = miclub.ScrapeTimes(timeslotURL)
+	p, ok := provider.Lookup(courses[selectedCourse].WebsiteType)
+	if !ok {
+		fmt.Printf("Unknown website type '%s' for course '%s'\n", courses[selectedCourse].WebsiteType, selectedCourse)
+		return nil
+	}
 
-	} else if strings.EqualFold(courses[selectedCourse].WebsiteType, "quick18") {
-		qTimes, e := quick18.ScrapeTimes(timeslotURL)
-		err = e
-		availableTimes = qTimes
+	if courseTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, courseTimeout)
+		defer cancel()
 	}
 
+	availableTimes, err := p.ScrapeTimes(ctx, f, timeslotURL, selectedGame)
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Printf("Timed out after %s scraping times for %s at %s (try a longer --timeout)\n", courseTimeout, selectedGame, selectedCourse)
+		return nil
+	}
 	if err != nil {
 		fmt.Printf("Failed to scrape times for %s at %s: %v\n", selectedGame, selectedCourse, err)
-		return
+		return nil
 	}
 
 	if len(availableTimes) == 0 {
 		fmt.Printf("No available times found for %s at %s\n", selectedGame, selectedCourse)
-		return
-	}
-
-	// Filter out columns not matching the user's chosen selectedGame
-	if strings.EqualFold(courses[selectedCourse].WebsiteType, "quick18") {
-		filteredMap := map[string][]shared.TeeTimeSlot{}
-		if timesForGame, ok := availableTimes[selectedGame]; ok {
-			filteredMap[selectedGame] = timesForGame
-		}
-		availableTimes = filteredMap
+		return nil
 	}
 
 	sortedLayouts, layoutTimes := sortTimesByLayoutAndSpots(availableTimes, filterStartMinutes, filterEndMinutes, spots)
 
 	if len(sortedLayouts) == 0 {
 		fmt.Println("No available times with the specified filters.")
-		return
+		return nil
 	}
 
-	displaySortedTimes(layoutTimes, sortedLayouts)
+	return displaySortedTimes(layoutTimes, sortedLayouts)
 }
 
 func sortTimesByLayoutAndSpots(availableTimes map[string][]shared.TeeTimeSlot, filterStartMinutes, filterEndMinutes, spots int) ([]string, map[string][]shared.TeeTimeSlot) {
@@ -632,48 +858,97 @@ func sortTimesByLayoutAndSpots(availableTimes map[string][]shared.TeeTimeSlot, f
 }
 
 func loadCourses() (map[string]CourseConfig, error) {
-	courses := make(map[string]CourseConfig)
-	file, err := os.Open(configPath)
-	if err != nil {
+	if demoMode {
+		return demoCourseConfigs(), nil
+	}
+
+	cfg, err := config.Load(configPath)
+	if errors.Is(err, os.ErrNotExist) {
 		fmt.Println("[WARNING] Can't read config file. If this is your first time running TeeTimeFinder, you must run `TeeTimeFinder config` first.")
 		fmt.Println()
 		return nil, fmt.Errorf("failed to open config file: %w", err)
 	}
-	defer file.Close()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file:\n%w", err)
+	}
+	return courseConfigs(cfg), nil
+}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, ",", 4)
-		if len(parts) < 3 {
-			continue
+// Helper function to key the config's courses by name for searching
+func courseConfigs(cfg *config.Config) map[string]CourseConfig {
+	courses := make(map[string]CourseConfig, len(cfg.Courses))
+	for _, c := range cfg.Courses {
+		courses[c.Name] = CourseConfig{
+			URL:         c.URL,
+			WebsiteType: c.WebsiteType,
+			Aliases:     c.Aliases,
+			Tags:        c.Tags,
+			Blacklisted: c.Blacklisted,
 		}
+	}
+	return courses
+}
 
-		courseName := strings.TrimSpace(parts[0])
-		courseURL := strings.TrimSpace(parts[1])
-		websiteType := strings.TrimSpace(parts[2])
+// Moves an old config.txt over to the YAML config the first time we run
+func migrateConfig() error {
+	// a config picked with --config or TEETIMEFINDER_CONFIG is never one the
+	// old versions wrote, so a config.txt next to it isn't ours to move
+	if configPath != standardConfigPath() {
+		return nil
+	}
 
-		blacklisted := false
-		if len(parts) == 4 {
-			bl := strings.TrimSpace(parts[3])
-			blacklisted = strings.EqualFold(bl, "true")
-		}
+	backup, err := config.Migrate(configPath, legacyConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to move %s to %s, fix it and try again:\n%w", config.LegacyFile, filepath.Base(configPath), err)
+	}
+	if backup != "" {
+		fmt.Fprintf(os.Stderr, "Moved your courses to %s, the old config is saved as %s\n", configPath, backup)
+	}
+	return nil
+}
 
-		courses[courseName] = CourseConfig{
-			URL:         courseURL,
-			WebsiteType: websiteType,
-			Blacklisted: blacklisted,
-		}
+// Keeps the defaults from the config for any search flags not given. They
+// stay apart from the flags so the form can offer them without locking
+// them, and a default time that's already gone by today can be skipped.
+func applyConfigDefaults(cmd *cobra.Command) {
+	configDefaults = config.Defaults{}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return // the search reports a broken config when it loads the courses
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+	flags := cmd.Flags()
+	d := cfg.Defaults
+	if !flags.Changed("time") {
+		configDefaults.Time = d.Time
+	}
+	if !flags.Changed("spots") {
+		configDefaults.Spots = d.Spots
+	}
+	if !flags.Changed("days") && !flags.Changed("from") && !flags.Changed("to") {
+		configDefaults.Days = d.Days
+	}
+	if d.Workers != 0 && !flags.Changed("workers") {
+		scrapeWorkers = d.Workers
+	}
+}
+
+// Takes the time and spots from the start form. A default left as it was
+// stays a default, one that was changed or cleared is replaced by what's
+// in the form.
+func applyStartAnswers(ans startAnswers) {
+	if ans.time != configDefaults.Time {
+		configDefaults.Time = ""
+		specifiedTime = ans.time
 	}
 
-	return courses, nil
+	spots, _ := strconv.Atoi(ans.spots)
+	if spots != configDefaults.Spots {
+		configDefaults.Spots = 0
+		if spots > 0 {
+			specifiedSpots = spots
+		}
+	}
 }
 
 func handleDateInput() (time.Time, error) {
@@ -694,22 +969,104 @@ func handleDateInput() (time.Time, error) {
 	return dt, nil
 }
 
+// handleDateRange returns every date to search. Without --from, --to or
+// --days it's just the single date from -d or the form.
+func handleDateRange() ([]time.Time, error) {
+	days := searchDays
+	if days == 0 && fromDate == "" && toDate == "" {
+		days = configDefaults.Days
+	}
+
+	if fromDate == "" && toDate == "" && days == 0 {
+		dt, err := handleDateInput()
+		if err != nil {
+			return nil, err
+		}
+		return []time.Time{dt}, nil
+	}
+
+	if fromDate != "" && specifiedDate != "" && fromDate != specifiedDate {
+		return nil, fmt.Errorf("use either --date or --from, not both")
+	}
+	if toDate != "" && days > 0 {
+		return nil, fmt.Errorf("use either --to or --days, not both")
+	}
+	if days < 0 {
+		return nil, fmt.Errorf("days must be a positive number")
+	}
+
+	// start of the range: --from, then -d, then today
+	today, _ := time.Parse("02-01-2006", time.Now().Format("02-01-2006"))
+	from := today
+	switch {
+	case fromDate != "":
+		dt, err := time.Parse("02-01-2006", fromDate)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid date %q – use DD-MM-YYYY", fromDate)
+		}
+		from = dt
+	case specifiedDate != "":
+		dt, err := time.Parse("02-01-2006", specifiedDate)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid date %q – use DD-MM-YYYY", specifiedDate)
+		}
+		from = dt
+	}
+
+	to := from
+	switch {
+	case toDate != "":
+		dt, err := time.Parse("02-01-2006", toDate)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid date %q – use DD-MM-YYYY", toDate)
+		}
+		to = dt
+	case days > 0:
+		to = from.AddDate(0, 0, days-1)
+	}
+
+	if from.Before(today) {
+		return nil, fmt.Errorf("Selected date is in the past")
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("End date %s is before the start date %s", to.Format("02-01-2006"), from.Format("02-01-2006"))
+	}
+
+	var dates []time.Time
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d)
+	}
+	if len(dates) > maxSearchDays {
+		return nil, fmt.Errorf("can only search up to %d days at a time", maxSearchDays)
+	}
+	return dates, nil
+}
+
 func handleTimeInput() (int /*start*/, int /*end*/, error) {
-	if specifiedTime == "" { // user left it blank
+	timeStr, isDefault := specifiedTime, false
+	if timeStr == "" {
+		timeStr, isDefault = configDefaults.Time, true
+	}
+	if timeStr == "" { // user left it blank
 		return 0, 0, nil // no filter
 	}
 
-	mins, err := parseTimeToMinutes24(specifiedTime)
+	mins, err := parseTimeToMinutes24(timeStr)
 	if err != nil {
-		return 0, 0, fmt.Errorf("invalid time %q – use HH:MM (24-hour)", specifiedTime)
+		return 0, 0, fmt.Errorf("invalid time %q – use HH:MM (24-hour)", timeStr)
 	}
 
-	// if they chose today's date, make sure the time isn't already past
+	// if they chose today's date, make sure the time isn't already past. A
+	// default time that's gone by is just skipped, it wasn't asked for.
 	now := time.Now()
 	if globalSelectedDate.Year() == now.Year() &&
 		globalSelectedDate.YearDay() == now.YearDay() &&
 		mins < now.Hour()*60+now.Minute() {
-		return 0, 0, fmt.Errorf("specified time %s is already in the past", specifiedTime)
+		if isDefault {
+			debugPrintf("Default time %s has already gone by today, not filtering by time\n", timeStr)
+			return 0, 0, nil
+		}
+		return 0, 0, fmt.Errorf("specified time %s is already in the past", timeStr)
 	}
 
 	return mins - 60 /*start*/, mins + 60 /*end*/, nil
@@ -732,7 +1089,7 @@ func parseTimeToMinutes(timeStr string) (int, error) {
 			return t.Hour()*60 + t.Minute(), nil
 		}
 	}
-	fmt.Printf("Failed to parse timeStr '%s' with any known layout\n", timeStr)
+	debugPrintf("Failed to parse timeStr '%s' with any known layout\n", timeStr)
 	return 0, fmt.Errorf("failed to parse time '%s'", timeStr)
 }
 
@@ -763,6 +1120,7 @@ func formatMinutesAs12Hour(totalMins int) string {
 	return fmt.Sprintf("%02d:%02d %s", hour12, minute, suffix)
 }
 
+// Finds the course with this exact name or alias, ignoring case
 func findCourseInsensitive(all map[string]CourseConfig, typed string) (string, bool) {
 	lower_typed := strings.ToLower(strings.TrimSpace(typed))
 	for k := range all {
@@ -770,50 +1128,114 @@ func findCourseInsensitive(all map[string]CourseConfig, typed string) (string, b
 			return k, true
 		}
 	}
+	for k, cfg := range all {
+		for _, alias := range cfg.Aliases {
+			if strings.ToLower(strings.TrimSpace(alias)) == lower_typed {
+				return k, true
+			}
+		}
+	}
 	return "", false
 }
 
-func scrapeCourseData(courses map[string]CourseConfig, selectedDate time.Time) ([]string, []string, map[string]map[string]string) {
+func scrapeCourseData(ctx context.Context, f *fetch.Fetcher, courses map[string]CourseConfig, selectedDate time.Time) ([]string, []string, map[string]map[string]string, map[string]error) {
 	var standardGames, promoGames []string
 	gameToTimeslotURLs := make(map[string]map[string]string)
+	failures := make(map[string]error)
 	var scraped = 0
 
-	for courseName, cfg := range courses {
-		progressProgram.Send(logMsg(
-			fmt.Sprintf("Scraping URL for course %s: %s\n", courseName, cfg.URL),
-		))
+	scrapeCourses(ctx, courses,
+		func(ctx context.Context, courseName string, cfg CourseConfig) (map[string]string, error) {
+			sendScrapeLog(courseName, cfg)
 
-		var (
-			gameTimeslotURLs map[string]string
-			err              error
-		)
+			p, ok := provider.Lookup(cfg.WebsiteType)
+			if !ok {
+				return nil, fmt.Errorf("unknown website type '%s'", cfg.WebsiteType)
+			}
+			return p.ScrapeDates(ctx, f, cfg.URL, selectedDate)
+		},
+		func(courseName string, gameTimeslotURLs map[string]string, err error) {
+			scraped++
+			if progressProgram != nil {
+				progressProgram.Send(pbMsg(scraped))
+			}
 
-		// Branch based on website type
-		if strings.EqualFold(cfg.WebsiteType, "miclub") {
-			gameTimeslotURLs, err = miclub.ScrapeDates(cfg.URL, selectedDate)
-		} else if strings.EqualFold(cfg.WebsiteType, "quick18") {
-			// Placeholder logic for quick18
-			// gameTimeslotURLs, err = quick18.ScrapeDates(cfg.URL, selectedDate)
-			//fmt.Println("Quick18 support not implemented yet... Skipping")
-			gameTimeslotURLs, err = quick18.ScrapeDates(cfg.URL, selectedDate)
-		} else {
-			fmt.Printf("Unknown website type '%s' for course '%s'. Skipping.\n", cfg.WebsiteType, courseName)
-			continue
-		}
+			if err != nil {
+				failures[courseName] = err
+				return
+			}
+			standardGames, promoGames, gameToTimeslotURLs = categoriseGames(gameTimeslotURLs, courseName, standardGames, promoGames, gameToTimeslotURLs)
+		},
+	)
 
-		if err != nil {
-			fmt.Printf("Failed to scrape %s: %v\n", courseName, err)
-			continue
-		}
+	return standardGames, promoGames, gameToTimeslotURLs, failures
+}
+
+// scrapeCourseDataRange is scrapeCourseData for a range of dates. Each
+// course's calendar is read once for the whole range and the games found are
+// grouped by date, earliest first.
+func scrapeCourseDataRange(ctx context.Context, f *fetch.Fetcher, courses map[string]CourseConfig, from, to time.Time) ([]dayResult, map[string]error) {
+	byDate := make(map[string]*dayResult)
+	failures := make(map[string]error)
+	var scraped = 0
+
+	scrapeCourses(ctx, courses,
+		func(ctx context.Context, courseName string, cfg CourseConfig) (map[string]map[string]string, error) {
+			sendScrapeLog(courseName, cfg)
+
+			p, ok := provider.Lookup(cfg.WebsiteType)
+			if !ok {
+				return nil, fmt.Errorf("unknown website type '%s'", cfg.WebsiteType)
+			}
+			return p.ScrapeDateRange(ctx, f, cfg.URL, from, to)
+		},
+		func(courseName string, dateToGames map[string]map[string]string, err error) {
+			scraped++
+			if progressProgram != nil {
+				progressProgram.Send(pbMsg(scraped))
+			}
+
+			if err != nil {
+				failures[courseName] = err
+				return
+			}
+
+			for day, games := range dateToGames {
+				date, err := time.Parse("2006-01-02", day)
+				if err != nil {
+					debugPrintf("Skipping unexpected date '%s' for course '%s'\n", day, courseName)
+					continue
+				}
+
+				r := byDate[day]
+				if r == nil {
+					r = &dayResult{date: date, gameToTimeslotURLs: make(map[string]map[string]string)}
+					byDate[day] = r
+				}
+				r.standardGames, r.promoGames, r.gameToTimeslotURLs = categoriseGames(games, courseName, r.standardGames, r.promoGames, r.gameToTimeslotURLs)
+			}
+		},
+	)
 
-		standardGames, promoGames, gameToTimeslotURLs = categoriseGames(gameTimeslotURLs, courseName, standardGames, promoGames, gameToTimeslotURLs)
-		scraped++
-		if progressProgram != nil {
-			progressProgram.Send(pbMsg(scraped))
+	days := make([]dayResult, 0, len(byDate))
+	for _, r := range byDate {
+		if len(r.standardGames) > 0 || len(r.promoGames) > 0 {
+			days = append(days, *r)
 		}
 	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].date.Before(days[j].date)
+	})
+	return days, failures
+}
 
-	return standardGames, promoGames, gameToTimeslotURLs
+// Helper function to show which course is being scraped above the progress bar
+func sendScrapeLog(courseName string, cfg CourseConfig) {
+	if progressProgram != nil {
+		progressProgram.Send(logMsg(
+			fmt.Sprintf("Scraping URL for course %s: %s\n", courseName, cfg.URL),
+		))
+	}
 }
 
 func categoriseGames(gameTimeslotURLs map[string]string, courseName string, standardGames, promoGames []string, gameToTimeslotURLs map[string]map[string]string) ([]string, []string, map[string]map[string]string) {
@@ -904,6 +1326,46 @@ func normaliseGameName(originalName string) string {
 	return strings.Title(name)
 }
 
+// promptDateSelection lists each date with how many courses have games on it
+// and returns the index of the chosen day, or -1 if cancelled.
+func promptDateSelection(days []dayResult) int {
+	options := make([]string, len(days))
+	for i, day := range days {
+		courses := make(map[string]bool)
+		for _, courseMap := range day.gameToTimeslotURLs {
+			for course := range courseMap {
+				courses[course] = true
+			}
+		}
+		options[i] = formatDayOption(day.date, len(courses))
+	}
+
+	choice, ok, err := selectFromList("Select a date", options)
+	if err != nil {
+		fmt.Printf("TUI error: %v\n", err)
+		return -1
+	}
+	if !ok || choice == "" {
+		return -1 // cancelled
+	}
+
+	for i, opt := range options {
+		if opt == choice {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatDayOption renders a date for the date picker, e.g. "Sat 27-09-2025 (3 courses)"
+func formatDayOption(date time.Time, courses int) string {
+	noun := "courses"
+	if courses == 1 {
+		noun = "course"
+	}
+	return fmt.Sprintf("%s (%d %s)", date.Format("Mon 02-01-2006"), courses, noun)
+}
+
 func promptGameSelection(standardGames, promoGames []string, _ map[string]map[string]string) string {
 	gameOptions := uniqueNames(standardGames)
 	if len(promoGames) > 0 {
@@ -991,19 +1453,39 @@ func sortTimesByLayout(availableTimes map[string][]shared.TeeTimeSlot, filterSta
 	return sortedLayouts, layoutTimes
 }
 
-func displaySortedTimes(layoutTimes map[string][]shared.TeeTimeSlot, sortedLayouts []string) {
-	// build one string per timeslot
-	var lines []string
+// displaySortedTimes shows the times in the pager and returns the tee time
+// the user picked, or nil if they left without picking one.
+func displaySortedTimes(layoutTimes map[string][]shared.TeeTimeSlot, sortedLayouts []string) *shared.TeeTimeSlot {
+	// build one line per layout heading and timeslot
+	var lines []pagerLine
 	for _, layout := range sortedLayouts {
-		lines = append(lines, fmt.Sprintf("%s:", layout))
+		lines = append(lines, pagerLine{text: fmt.Sprintf("%s:", layout)})
 		for _, timeSlot := range layoutTimes[layout] {
 			prettyTime := reSpaceAMPMRegex.ReplaceAllString(timeSlot.Time, "$1 $2")
-			lines = append(lines, fmt.Sprintf("%s: %d spots available\n", prettyTime, timeSlot.AvailableSpots))
+			line := fmt.Sprintf("%s: %d spots available", prettyTime, timeSlot.AvailableSpots)
+			if fees := formatFees(timeSlot.Fees); fees != "" {
+				line += " – " + fees
+			}
+			lines = append(lines, pagerLine{text: line + "\n", slot: &timeSlot})
 		}
 	}
 
 	// launch pager
-	_, _ = tea.NewProgram(newPagerModel(lines), tea.WithAltScreen()).Run()
+	res, err := tea.NewProgram(newPagerModel(lines), tea.WithAltScreen()).Run()
+	if err != nil {
+		fmt.Printf("TUI error: %v\n", err)
+		return nil
+	}
+	return res.(pagerModel).chosen
+}
+
+// formatFees joins a slot's green fees into one line for the pager
+func formatFees(fees []shared.Fee) string {
+	parts := make([]string, 0, len(fees))
+	for _, f := range fees {
+		parts = append(parts, f.String())
+	}
+	return strings.Join(parts, ", ")
 }
 
 func readInput() string {
@@ -1030,30 +1512,53 @@ func uniqueNames(items []string) []string {
 }
 
 // bubbletea logic
-func newStartFormModel(courseNames []string, blacklist map[string]bool) startFormModel {
+func newStartFormModel(all map[string]CourseConfig) startFormModel {
+	var courseNames []string
+	for n := range all {
+		courseNames = append(courseNames, n)
+	}
+	sort.Strings(courseNames)
+	tags := courseTags(all)
+
 	prefilled := []string{
 		strings.Join(courseList, ", "), // –c
-		specifiedDate,                  // –d
-		specifiedTime,                  // –t
-		func() string { // –s
+		startDateValue(),               // –d / --from
+		func() string { // –t, or the config's default
+			if specifiedTime != "" {
+				return specifiedTime
+			}
+			return configDefaults.Time
+		}(),
+		func() string { // –s, or the config's default
 			if specifiedSpots > 0 {
 				return strconv.Itoa(specifiedSpots)
 			}
+			if configDefaults.Spots > 0 {
+				return strconv.Itoa(configDefaults.Spots)
+			}
 			return ""
 		}(),
 	}
 	locked := []bool{
 		len(courseList) > 0,
-		specifiedDate != "",
+		specifiedDate != "" || fromDate != "",
 		specifiedTime != "",
 		specifiedSpots > 0,
+		len(groupList) > 0 || len(tags) == 0, // –g
 	}
 
 	m := startFormModel{
-		in:        make([]textinput.Model, 4),
-		locked:    locked,
-		courses:   courseNames,
-		blacklist: blacklist,
+		in:      make([]textinput.Model, 4),
+		locked:  locked,
+		all:     all,
+		courses: courseNames,
+		tags:    tags,
+		picked:  make(map[string]bool),
+	}
+	for _, g := range groupList {
+		for _, tag := range splitTags(g) {
+			m.picked[strings.ToLower(tag)] = true
+		}
 	}
 
 	placeholders := []string{
@@ -1080,10 +1585,12 @@ func newStartFormModel(courseNames []string, blacklist map[string]bool) startFor
 	}
 
 	// first editable field (if any) gets initial focus
-	for idx := 0; idx < len(m.in); idx++ {
+	for _, idx := range startFieldOrder {
 		if !locked[idx] {
 			m.focus = idx
-			m.in[idx].Focus()
+			if idx < len(m.in) {
+				m.in[idx].Focus()
+			}
 			break
 		}
 	}
@@ -1091,22 +1598,48 @@ func newStartFormModel(courseNames []string, blacklist map[string]bool) startFor
 	return m
 }
 
+// helper: what to show in the form's date field. A --from range is shown
+// as "27-09-2025 to 28-09-2025" (or "for 3 days" with --days).
+func startDateValue() string {
+	if fromDate == "" {
+		return specifiedDate
+	}
+	switch {
+	case toDate != "":
+		return fromDate + " to " + toDate
+	case searchDays > 1:
+		return fmt.Sprintf("%s for %d days", fromDate, searchDays)
+	}
+	return fromDate
+}
+
+// helper: where a field is shown on the form
+func fieldPosition(idx int) int {
+	for pos, f := range startFieldOrder {
+		if f == idx {
+			return pos
+		}
+	}
+	return 0
+}
+
 // helper: find the next editable field when the user navigates
 func (m startFormModel) nextEditable(from, dir int) int {
-	n := len(m.in)
+	n := len(startFieldOrder)
+	pos := fieldPosition(from)
 	for i := 0; i < n; i++ {
-		from = (from + dir + n) % n
-		if !m.locked[from] {
-			return from
+		pos = (pos + dir + n) % n
+		if !m.locked[startFieldOrder[pos]] {
+			return startFieldOrder[pos]
 		}
 	}
 	return from // every field is locked
 }
 
-// true when `idx` is the highest-index editable input
+// true when `idx` is the last editable field on the form
 func (m startFormModel) isLastEditable(idx int) bool {
-	for i := idx + 1; i < len(m.in); i++ {
-		if !m.locked[i] {
+	for _, f := range startFieldOrder[fieldPosition(idx)+1:] {
+		if !m.locked[f] {
 			return false
 		}
 	}
@@ -1143,6 +1676,21 @@ func (m startFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "down", "tab":
 			m.focus = m.nextEditable(m.focus, +1)
+
+		// the group picker moves along the tags and toggles them
+		case "left":
+			if m.focus == groupsField && m.tagCursor > 0 {
+				m.tagCursor--
+			}
+		case "right":
+			if m.focus == groupsField && m.tagCursor < len(m.tags)-1 {
+				m.tagCursor++
+			}
+		case " ":
+			if m.focus == groupsField {
+				tag := strings.ToLower(m.tags[m.tagCursor])
+				m.picked[tag] = !m.picked[tag]
+			}
 		}
 	}
 
@@ -1160,7 +1708,7 @@ func (m startFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	var cmd tea.Cmd
-	if !m.locked[m.focus] {
+	if m.focus < len(m.in) && !m.locked[m.focus] {
 		m.in[m.focus], cmd = m.in[m.focus].Update(msg)
 	}
 	return m, cmd
@@ -1170,47 +1718,131 @@ func (m startFormModel) View() string {
 	var b strings.Builder
 	b.WriteString("TeeTimeFinder – start-up options\n\n")
 
-	labels := []string{"Courses:", "Date:", "Time:", "Minimum spots:"}
-	for i, input := range m.in {
+	labels := []string{"Courses:", "Date:", "Time:", "Minimum spots:", "Groups:"}
+	for _, i := range startFieldOrder {
+		if i == groupsField {
+			if len(m.tags) > 0 {
+				b.WriteString(labels[i] + "\n")
+				b.WriteString(m.groupsView() + "\n\n")
+			}
+			continue
+		}
 		b.WriteString(labels[i] + "\n")
-		b.WriteString(input.View() + "\n\n")
+		b.WriteString(m.in[i].View() + "\n")
+		if i == 0 && !m.locked[0] {
+			b.WriteString(m.matchesView())
+		}
+		b.WriteString("\n")
 	}
 
 	// show available course names
 	if len(m.courses) > 0 {
 		b.WriteString("Available courses (from config):\n")
 		for _, c := range m.courses {
-			if m.blacklist[c] {
-				b.WriteString(" • " + blacklistStyle.Render(c) + "\n")
-			} else {
-				b.WriteString(" • " + c + "\n")
+			name := c
+			if m.all[c].Blacklisted {
+				name = blacklistStyle.Render(c)
 			}
+			if aliases := m.all[c].Aliases; len(aliases) > 0 {
+				name += " (" + strings.Join(aliases, ", ") + ")"
+			}
+			b.WriteString(" • " + name + "\n")
 		}
 		b.WriteString("\n")
 	}
 
-	b.WriteString(controlStyle.Render("[Enter]: next | [Esc]: quit"))
+	controls := "[Enter]: next | [Esc]: quit"
+	if !m.locked[groupsField] {
+		controls = "[Enter]: next | [←/→]: move | [Space]: pick group | [Esc]: quit"
+	}
+	b.WriteString(controlStyle.Render(controls))
+	return b.String()
+}
+
+// helper: which course each name typed in the course field matches, so a
+// typo shows up before the search starts
+func (m startFormModel) matchesView() string {
+	var b strings.Builder
+	for _, typed := range strings.Split(m.in[0].Value(), ",") {
+		typed = strings.TrimSpace(typed)
+		if typed == "" {
+			continue
+		}
+		name, suggestions := matchCourse(m.all, typed)
+		switch {
+		case name != "":
+			b.WriteString(controlStyle.Render("  "+typed+" → "+name) + "\n")
+		case len(suggestions) > 0:
+			b.WriteString(errorStyle.Render("  "+typed+": did you mean "+orList(suggestions)+"?") + "\n")
+		default:
+			b.WriteString(errorStyle.Render("  "+typed+" isn't in the config") + "\n")
+		}
+	}
 	return b.String()
 }
 
+// helper: the row of tags in the group picker, with the picked ones ticked
+func (m startFormModel) groupsView() string {
+	cells := make([]string, len(m.tags))
+	for i, tag := range m.tags {
+		box := "[ ] "
+		if m.picked[strings.ToLower(tag)] {
+			box = "[x] "
+		}
+		style := defaultStyle
+		if m.focus == groupsField && i == m.tagCursor {
+			style = hoverStyle
+		}
+		cells[i] = style.Render(box + tag)
+	}
+	return strings.Join(cells, "  ")
+}
+
+// helper: the tags picked in the form, in the order they're shown
+func (m startFormModel) pickedGroups() []string {
+	var groups []string
+	for _, tag := range m.tags {
+		if m.picked[strings.ToLower(tag)] {
+			groups = append(groups, tag)
+		}
+	}
+	return groups
+}
+
 type startAnswers struct {
 	courseChoice string
+	groups       []string
 	date         string
 	time         string
 	spots        string
 }
 
-func collectStartAnswers(allCourses map[string]CourseConfig) (startAnswers, error) {
-	// turn the map keys into an alphabetically-sorted slice
-	var names []string
-	bl := make(map[string]bool)
-	for n, cfg := range allCourses {
+// courseTags returns every tag used by a course, sorted and without
+// duplicates (ignoring case, the first spelling wins)
+func courseTags(courses map[string]CourseConfig) []string {
+	names := make([]string, 0, len(courses))
+	for n := range courses {
 		names = append(names, n)
-		bl[n] = cfg.Blacklisted
 	}
 	sort.Strings(names)
 
-	p := tea.NewProgram(newStartFormModel(names, bl), tea.WithAltScreen())
+	seen := make(map[string]bool)
+	var tags []string
+	for _, n := range names {
+		for _, t := range courses[n].Tags {
+			t = strings.TrimSpace(t)
+			if key := strings.ToLower(t); !seen[key] {
+				seen[key] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return strings.ToLower(tags[i]) < strings.ToLower(tags[j]) })
+	return tags
+}
+
+func collectStartAnswers(allCourses map[string]CourseConfig) (startAnswers, error) {
+	p := tea.NewProgram(newStartFormModel(allCourses), tea.WithAltScreen())
 	model, err := p.Run()
 	if err != nil {
 		return startAnswers{}, err
@@ -1219,6 +1851,7 @@ func collectStartAnswers(allCourses map[string]CourseConfig) (startAnswers, erro
 
 	return startAnswers{
 		courseChoice: strings.TrimSpace(m.in[0].Value()),
+		groups:       m.pickedGroups(),
 		date:         strings.TrimSpace(m.in[1].Value()),
 		time:         strings.TrimSpace(m.in[2].Value()),
 		spots:        strings.TrimSpace(m.in[3].Value()),
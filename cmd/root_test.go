@@ -6,16 +6,56 @@
 package cmd
 
 import (
+	"context"
+	"net/url"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/provider"
 	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// stubProvider serves canned calendars keyed by course URL so the CLI can be
+// tested without a booking site
+type stubProvider struct{}
+
+var stubCalendars = map[string]map[string]map[string]string{} // course URL -> date -> game -> timeslot URL
+var stubTimes = map[string]map[string][]shared.TeeTimeSlot{}  // timeslot URL -> layout -> slots
+
+func init() {
+	provider.Register(stubProvider{})
+}
+
+func (stubProvider) Name() string                   { return "stub" }
+func (stubProvider) Detect(*url.URL) bool           { return false }
+func (stubProvider) NormaliseURL(u *url.URL) string { return u.String() }
+func (stubProvider) BookingURL(u string) string     { return u }
+
+func (stubProvider) ScrapeDates(_ context.Context, _ *fetch.Fetcher, baseURL string, selectedDate time.Time) (map[string]string, error) {
+	return stubCalendars[baseURL][selectedDate.Format("2006-01-02")], nil
+}
+
+func (stubProvider) ScrapeDateRange(_ context.Context, _ *fetch.Fetcher, baseURL string, from, to time.Time) (map[string]map[string]string, error) {
+	out := make(map[string]map[string]string)
+	for day, games := range stubCalendars[baseURL] {
+		if day >= from.Format("2006-01-02") && day <= to.Format("2006-01-02") {
+			out[day] = games
+		}
+	}
+	return out, nil
+}
+
+func (stubProvider) ScrapeTimes(_ context.Context, _ *fetch.Fetcher, timeslotURL, _ string) (map[string][]shared.TeeTimeSlot, error) {
+	return stubTimes[timeslotURL], nil
+}
+
 func TestLoadCourses(t *testing.T) {
 	t.Run("Load Valid Courses", func(t *testing.T) {
 		// Use temporary config file
@@ -109,6 +149,90 @@ func TestHandleDateInput(t *testing.T) {
 	})
 }
 
+func TestHandleDateRange(t *testing.T) {
+	// Save and restore globals
+	origDate, origFrom, origTo, origDays := specifiedDate, fromDate, toDate, searchDays
+	defer func() { specifiedDate, fromDate, toDate, searchDays = origDate, origFrom, origTo, origDays }()
+
+	day := func(n int) string { return time.Now().AddDate(0, 0, n).Format("02-01-2006") }
+
+	cases := []struct {
+		name                string
+		date, from, to      string
+		days                int
+		wantFirst, wantLast string
+		wantLen             int
+		wantErr             bool
+	}{
+		{name: "Single date without range flags", date: day(1), wantFirst: day(1), wantLast: day(1), wantLen: 1},
+		{name: "From and to", from: day(2), to: day(4), wantFirst: day(2), wantLast: day(4), wantLen: 3},
+		{name: "Days from --date", date: day(1), days: 2, wantFirst: day(1), wantLast: day(2), wantLen: 2},
+		{name: "Days from today", days: 3, wantFirst: day(0), wantLast: day(2), wantLen: 3},
+		{name: "To before from", from: day(4), to: day(2), wantErr: true},
+		{name: "Both to and days", from: day(1), to: day(2), days: 2, wantErr: true},
+		{name: "Range in the past", from: "17-08-2023", to: day(1), wantErr: true},
+		{name: "Range too long", days: maxSearchDays + 1, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			specifiedDate, fromDate, toDate, searchDays = c.date, c.from, c.to, c.days
+
+			dates, err := handleDateRange()
+			if c.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, dates, c.wantLen)
+			assert.Equal(t, c.wantFirst, dates[0].Format("02-01-2006"))
+			assert.Equal(t, c.wantLast, dates[len(dates)-1].Format("02-01-2006"))
+		})
+	}
+}
+
+func TestScrapeCourseDataRange(t *testing.T) {
+	stubCalendars = map[string]map[string]map[string]string{
+		"https://north.test": {
+			"2025-09-27": {"18 Holes": "https://north.test/sat/18"},
+			"2025-09-28": {"9 Holes": "https://north.test/sun/9", "Twilight Special": "https://north.test/sun/promo"},
+		},
+		"https://south.test": {
+			"2025-09-28": {"18 Holes": "https://south.test/sun/18"},
+			"2025-09-30": {"18 Holes": "https://south.test/tue/18"},
+		},
+	}
+	defer func() { stubCalendars = map[string]map[string]map[string]string{} }()
+
+	courses := map[string]CourseConfig{
+		"North": {URL: "https://north.test", WebsiteType: "stub"},
+		"South": {URL: "https://south.test", WebsiteType: "stub"},
+	}
+
+	from, _ := time.Parse("2006-01-02", "2025-09-27")
+	days, failures := scrapeCourseDataRange(context.Background(), fetch.New(), courses, from, from.AddDate(0, 0, 1))
+	assert.Empty(t, failures)
+
+	// Dates come back in order and outside the range are dropped
+	require.Len(t, days, 2)
+	assert.Equal(t, "2025-09-27", days[0].date.Format("2006-01-02"))
+	assert.Equal(t, "2025-09-28", days[1].date.Format("2006-01-02"))
+
+	assert.Equal(t, []string{"18 Holes"}, days[0].standardGames)
+	assert.Equal(t, map[string]string{"North": "https://north.test/sat/18"}, days[0].gameToTimeslotURLs["18 Holes"])
+
+	assert.ElementsMatch(t, []string{"9 Holes", "18 Holes"}, days[1].standardGames)
+	assert.Equal(t, []string{"Twilight Special"}, days[1].promoGames)
+	assert.Equal(t, map[string]string{"South": "https://south.test/sun/18"}, days[1].gameToTimeslotURLs["18 Holes"])
+}
+
+func TestFormatDayOption(t *testing.T) {
+	sat, _ := time.Parse("2006-01-02", "2025-09-27")
+	assert.Equal(t, "Sat 27-09-2025 (3 courses)", formatDayOption(sat, 3))
+	assert.Equal(t, "Sat 27-09-2025 (1 course)", formatDayOption(sat, 1))
+}
+
 func TestHandleTimeInput(t *testing.T) {
 	t.Run("Test Valid times", func(t *testing.T) {
 		// Save and restore global
@@ -139,6 +263,35 @@ func TestHandleTimeInput(t *testing.T) {
 		assert.Equal(t, 0, start, "start time should be 0 on error")
 		assert.Equal(t, 0, end, "start time should be 0 on error")
 	})
+
+	t.Run("Default time already gone by today", func(t *testing.T) {
+		origTime, origDate, origDefaults := specifiedTime, globalSelectedDate, configDefaults
+		defer func() { specifiedTime, globalSelectedDate, configDefaults = origTime, origDate, origDefaults }()
+
+		now := time.Now()
+		if now.Hour() == 0 && now.Minute() == 0 {
+			t.Skip("nothing has gone by at midnight")
+		}
+		specifiedTime, globalSelectedDate = "", now
+		configDefaults.Time = "00:00"
+
+		start, end, err := handleTimeInput()
+		require.NoError(t, err, "a default that's gone by is skipped")
+		assert.Equal(t, 0, start)
+		assert.Equal(t, 0, end)
+
+		// The same time typed in is still an error
+		specifiedTime = "00:00"
+		_, _, err = handleTimeInput()
+		assert.ErrorContains(t, err, "already in the past")
+
+		// Another day uses the default
+		specifiedTime, globalSelectedDate = "", now.AddDate(0, 0, 1)
+		start, end, err = handleTimeInput()
+		require.NoError(t, err)
+		assert.Equal(t, -60, start)
+		assert.Equal(t, 60, end)
+	})
 }
 
 func TestParseTimeToMinutes(t *testing.T) {
@@ -221,6 +374,165 @@ func TestFindCourseInsensitive(t *testing.T) {
 		_, ok := findCourseInsensitive(all, "Unknown")
 		assert.False(t, ok)
 	})
+
+	t.Run("Alias", func(t *testing.T) {
+		all := map[string]CourseConfig{"The Springs Golf Course": {Aliases: []string{"Springs"}}}
+		name, ok := findCourseInsensitive(all, "springs")
+		assert.True(t, ok)
+		assert.Equal(t, "The Springs Golf Course", name)
+	})
+}
+
+func TestMatchCourse(t *testing.T) {
+	all := map[string]CourseConfig{
+		"The Springs Golf Course":      {},
+		"Hamersley Golf Course":        {Aliases: []string{"hamo"}},
+		"Fremantle Public Golf Course": {},
+		"Royal Fremantle Golf Club":    {},
+		"Collier Park Golf Course":     {Aliases: []string{"Collier"}},
+		"Collier Creek":                {},
+	}
+
+	tests := []struct {
+		typed       string
+		want        string
+		suggestions []string
+	}{
+		{"hamersley golf course", "Hamersley Golf Course", nil},
+		{"HAMO", "Hamersley Golf Course", nil},
+		{"collier", "Collier Park Golf Course", nil}, // an alias beats Collier Creek
+		{"springs", "The Springs Golf Course", nil},
+		{"royal fremantle", "Royal Fremantle Golf Club", nil},
+		{"hamersly", "Hamersley Golf Course", nil},
+		{"the sprngs", "The Springs Golf Course", nil},
+		{"fremantle", "", []string{"Fremantle Public Golf Course", "Royal Fremantle Golf Club"}},
+		{"golf", "", []string{"Collier Park Golf Course", "Fremantle Public Golf Course", "Hamersley Golf Course", "Royal Fremantle Golf Club", "The Springs Golf Course"}},
+		{"nowhere", "", nil},
+		{"ham x", "", nil},
+		{"  ", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typed, func(t *testing.T) {
+			name, suggestions := matchCourse(all, tt.typed)
+			assert.Equal(t, tt.want, name)
+			assert.Equal(t, tt.suggestions, suggestions)
+		})
+	}
+
+	t.Run("Shown in the form", func(t *testing.T) {
+		m := newStartFormModel(all)
+		m.in[0].SetValue("springs, fremantle, nowhere")
+		view := m.View()
+		assert.Contains(t, view, "springs → The Springs Golf Course")
+		assert.Contains(t, view, `fremantle: did you mean "Fremantle Public Golf Course" or "Royal Fremantle Golf Club"?`)
+		assert.Contains(t, view, "nowhere isn't in the config")
+		assert.Contains(t, view, "Hamersley Golf Course (hamo)")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := selectCourses(all, []string{"fremantle"}, nil)
+		assert.EqualError(t, err, `course 'fremantle' matches more than one course, did you mean "Fremantle Public Golf Course" or "Royal Fremantle Golf Club"`)
+
+		_, err = selectCourses(all, []string{"nowhere"}, nil)
+		assert.EqualError(t, err, "course 'nowhere' does not exist in config")
+	})
+}
+
+func TestSelectCourses(t *testing.T) {
+	all := map[string]CourseConfig{
+		"Hamersley Golf Course":    {URL: "u1", Tags: []string{"north"}},
+		"Collier Park Golf Course": {URL: "u2", Tags: []string{"South"}},
+		"Fremantle":                {URL: "u3", Tags: []string{"south", "coast"}},
+		"Wembley":                  {URL: "u4", Tags: []string{"north"}, Blacklisted: true},
+	}
+
+	t.Run("Everything but the blacklist", func(t *testing.T) {
+		got, err := selectCourses(all, nil, nil)
+		require.NoError(t, err)
+		assert.Len(t, got, 3)
+		assert.NotContains(t, got, "Wembley")
+	})
+
+	t.Run("By group", func(t *testing.T) {
+		got, err := selectCourses(all, nil, []string{"SOUTH"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"Collier Park Golf Course", "Fremantle"}, keys(got))
+	})
+
+	t.Run("Groups and names add up", func(t *testing.T) {
+		got, err := selectCourses(all, []string{"fremantle"}, []string{"north, coast"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"Hamersley Golf Course", "Fremantle"}, keys(got))
+	})
+
+	t.Run("Unknown group", func(t *testing.T) {
+		_, err := selectCourses(all, nil, []string{"country"})
+		assert.EqualError(t, err, "no courses are tagged 'country' in config")
+	})
+
+	t.Run("Everything selected is blacklisted", func(t *testing.T) {
+		_, err := selectCourses(all, []string{"wembley"}, nil)
+		assert.EqualError(t, err, "every course selected is blacklisted")
+
+		got, err := selectCourses(all, []string{"wembley", "hamersley"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Hamersley Golf Course"}, keys(got), "the rest are still searched")
+	})
+}
+
+func TestCourseTags(t *testing.T) {
+	all := map[string]CourseConfig{
+		"A": {Tags: []string{"south", "Coast"}},
+		"B": {Tags: []string{"South", " north "}},
+		"C": {},
+	}
+	assert.Equal(t, []string{"Coast", "north", "south"}, courseTags(all))
+}
+
+func TestStartFormGroupPicker(t *testing.T) {
+	oldCourses, oldGroups := courseList, groupList
+	defer func() { courseList, groupList = oldCourses, oldGroups }()
+	courseList, groupList = nil, nil
+
+	m := newStartFormModel(map[string]CourseConfig{
+		"A": {Tags: []string{"coast", "south"}},
+		"B": {Tags: []string{"north"}},
+	})
+	press := func(keys ...tea.KeyMsg) {
+		for _, k := range keys {
+			next, _ := m.Update(k)
+			m = next.(startFormModel)
+		}
+	}
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+
+	// The picker comes straight after the courses
+	press(tea.KeyMsg{Type: tea.KeyTab})
+	require.Equal(t, groupsField, m.focus)
+
+	press(tea.KeyMsg{Type: tea.KeyRight}, tea.KeyMsg{Type: tea.KeyRight}, space, tea.KeyMsg{Type: tea.KeyLeft}, tea.KeyMsg{Type: tea.KeyLeft}, space)
+	assert.Equal(t, []string{"coast", "south"}, m.pickedGroups())
+	assert.Empty(t, m.in[0].Value(), "picking groups doesn't type into the courses")
+
+	press(space)
+	assert.Equal(t, []string{"south"}, m.pickedGroups())
+
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, 1, m.focus, "enter moves on to the date")
+
+	t.Run("Locked by -g", func(t *testing.T) {
+		groupList = []string{"North"}
+		m := newStartFormModel(map[string]CourseConfig{"A": {Tags: []string{"north", "south"}}})
+		assert.Equal(t, []string{"north"}, m.pickedGroups())
+		assert.Equal(t, 1, m.nextEditable(0, +1), "the picker is skipped")
+	})
+
+	t.Run("Hidden with no tags", func(t *testing.T) {
+		groupList = nil
+		m := newStartFormModel(map[string]CourseConfig{"A": {}})
+		assert.Equal(t, 1, m.nextEditable(0, +1))
+		assert.NotContains(t, m.View(), "Groups:")
+	})
 }
 
 func TestIsStandardGame(t *testing.T) {
@@ -360,3 +672,42 @@ func TestHandleSpotsInput(t *testing.T) {
 		assert.False(t, used)
 	})
 }
+
+func TestFormatFees(t *testing.T) {
+	fees := []shared.Fee{
+		{Amount: 30, Currency: "$", Label: "Weekday 9H Peak"},
+		{Amount: 23.5, Currency: "$", Label: "Weekday 9H Peak - Concession", Concession: true},
+	}
+	assert.Equal(t, "$30.00 Weekday 9H Peak, $23.50 Weekday 9H Peak - Concession", formatFees(fees))
+	assert.Empty(t, formatFees(nil), "no fees should render nothing")
+}
+
+func TestPagerModelPicksSlot(t *testing.T) {
+	early := shared.TeeTimeSlot{Time: "07:00 AM", AvailableSpots: 4, BookingURL: "https://example.com/teetime/0700"}
+	late := shared.TeeTimeSlot{Time: "07:10 AM", AvailableSpots: 2, BookingURL: "https://example.com/teetime/0710"}
+	lines := []pagerLine{
+		{text: "18 Holes:"},
+		{text: "07:00 AM: 4 spots available", slot: &early},
+		{text: "9 Holes:"},
+		{text: "07:10 AM: 2 spots available", slot: &late},
+	}
+
+	m := newPagerModel(lines)
+	assert.Equal(t, 1, m.cursor, "cursor should start on the first tee time, not a heading")
+
+	// Moving down skips the layout heading
+	res, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = res.(pagerModel)
+	assert.Equal(t, 3, m.cursor)
+
+	// Can't move past the last tee time
+	res, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = res.(pagerModel)
+	assert.Equal(t, 3, m.cursor)
+
+	res, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = res.(pagerModel)
+	if assert.NotNil(t, m.chosen) {
+		assert.Equal(t, "https://example.com/teetime/0710", m.chosen.BookingURL)
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes for `search`, so scripts can tell the outcomes apart
+const (
+	exitFound  = 0 // at least one tee time matched
+	exitNone   = 1 // everything was searched but nothing matched
+	exitErrors = 2 // bad input, or nothing matched and some courses failed
+)
+
+// exitCode is set by commands that finish without a cobra error but still
+// want a non-zero exit status
+var exitCode int
+
+var outputFormat string
+
+// searchResult is one matching tee time, as written by `search`
+type searchResult struct {
+	Date       string       `json:"date"` // YYYY-MM-DD
+	Time       string       `json:"time"` // 24-hour HH:MM
+	Course     string       `json:"course"`
+	Game       string       `json:"game"`
+	Layout     string       `json:"layout"`
+	Spots      int          `json:"spots"`
+	Fees       []shared.Fee `json:"fees,omitempty"`
+	BookingURL string       `json:"booking_url,omitempty"`
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Search for tee times without the interactive prompts",
+	Long: `Search runs the same search as TeeTimeFinder using only the -d/-t/-s/-c flags
+(and --from/--to/--days) and writes every matching tee time to stdout.
+
+Exit codes:
+  0  at least one tee time was found
+  1  no tee times were found
+  2  the search couldn't run, or nothing was found and some courses failed`,
+	Example: `  TeeTimeFinder search -d 22-02-2025 -t 08:00 -s 4 --format json
+  TeeTimeFinder search --from 22-02-2025 --to 23-02-2025 -c "Hamersley Golf Course" --format csv`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		exitCode = runSearch(ctx, cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().StringVarP(&outputFormat, "format", "f", "table", "Output format: table, json or csv")
+
+	searchCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json", "csv"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// searchParams is everything a non-interactive search needs, worked out
+// from the flags
+type searchParams struct {
+	courses            map[string]CourseConfig
+	dates              []time.Time
+	filterStartMinutes int
+	filterEndMinutes   int
+	spots              int
+}
+
+// runSearch does a full search with no TUI and returns the exit code.
+// Results go to out, everything else (errors, failed courses) to errOut.
+func runSearch(ctx context.Context, out, errOut io.Writer) int {
+	format, err := checkOutputFormat()
+	if err != nil {
+		fmt.Fprintf(errOut, "Error: %v\n", err)
+		return exitErrors
+	}
+
+	params, err := searchParamsFromFlags()
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitErrors
+	}
+
+	f, err := newFetcher()
+	if err != nil {
+		fmt.Fprintf(errOut, "Error: %v\n", err)
+		return exitErrors
+	}
+	results, failures := searchTeeTimes(ctx, f, params)
+	if ctx.Err() != nil {
+		fmt.Fprintln(errOut, "Search cancelled.")
+		return exitErrors
+	}
+	printFailures(errOut, failures)
+	if notice := offlineNotice(f, time.Now()); notice != "" {
+		fmt.Fprintln(errOut, notice)
+	}
+	if demoMode {
+		fmt.Fprintln(errOut, demoNotice)
+	}
+
+	if err := writeResults(out, format, results); err != nil {
+		fmt.Fprintf(errOut, "Error writing results: %v\n", err)
+		return exitErrors
+	}
+
+	return searchExitCode(len(results), len(failures))
+}
+
+// checkOutputFormat validates --format and returns it in lower case
+func checkOutputFormat() (string, error) {
+	format := strings.ToLower(strings.TrimSpace(outputFormat))
+	if format != "table" && format != "json" && format != "csv" {
+		return "", fmt.Errorf("unknown format %q (use table, json or csv)", outputFormat)
+	}
+	return format, nil
+}
+
+// searchParamsFromFlags loads the config and checks the -c/-g/-d/-t/-s (and
+// --from/--to/--days) flags without prompting for anything
+func searchParamsFromFlags() (searchParams, error) {
+	courses, err := loadCourses()
+	if err != nil {
+		return searchParams{}, fmt.Errorf("Error loading courses: %v", err)
+	}
+
+	courses, err = selectCourses(courses, courseList, groupList)
+	if err != nil {
+		return searchParams{}, fmt.Errorf("Error: %v", err)
+	}
+	if len(courses) == 0 {
+		return searchParams{}, fmt.Errorf("Error: no courses in config to search")
+	}
+
+	dates, err := handleDateRange()
+	if err != nil {
+		return searchParams{}, err
+	}
+	if len(dates) == 1 {
+		globalSelectedDate = dates[0]
+	}
+
+	filterStartMinutes, filterEndMinutes, err := handleTimeInput()
+	if err != nil {
+		return searchParams{}, err
+	}
+
+	if _, err := handleSpotsInput(); err != nil {
+		return searchParams{}, err
+	}
+
+	return searchParams{
+		courses:            courses,
+		dates:              dates,
+		filterStartMinutes: filterStartMinutes,
+		filterEndMinutes:   filterEndMinutes,
+		spots:              specifiedSpots,
+	}, nil
+}
+
+// searchTeeTimes scrapes every course and returns the matching tee times
+// along with any courses that failed. Pages are fetched through f.
+func searchTeeTimes(ctx context.Context, f *fetch.Fetcher, params searchParams) ([]searchResult, map[string]error) {
+	days, failures := scrapeDays(ctx, f, params.courses, params.dates)
+	days, timeFailures := preScrapeDays(ctx, f, days, params.filterStartMinutes, params.filterEndMinutes, params.spots, params.courses)
+	for course, err := range timeFailures {
+		failures[course] = err
+	}
+	return collectResults(days), failures
+}
+
+// searchExitCode picks the exit code for a finished search. Failed courses
+// only matter when nothing was found, since then the answer may be wrong.
+func searchExitCode(found, failed int) int {
+	switch {
+	case found > 0:
+		return exitFound
+	case failed > 0:
+		return exitErrors
+	}
+	return exitNone
+}
+
+// collectResults flattens the pre-scraped times into one row per tee time,
+// ordered by date, time, course, game and layout
+func collectResults(days []dayResult) []searchResult {
+	var results []searchResult
+
+	for _, day := range days {
+		for game, courseMap := range day.preScraped {
+			for course, layouts := range courseMap {
+				for layout, slots := range layouts {
+					for _, slot := range slots {
+						m, err := parseTimeToMinutes(slot.Time)
+						if err != nil {
+							continue
+						}
+						results = append(results, searchResult{
+							Date:       day.date.Format("2006-01-02"),
+							Time:       fmt.Sprintf("%02d:%02d", m/60, m%60),
+							Course:     course,
+							Game:       game,
+							Layout:     layout,
+							Spots:      slot.AvailableSpots,
+							Fees:       slot.Fees,
+							BookingURL: slot.BookingURL,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Time != b.Time {
+			return a.Time < b.Time
+		}
+		if a.Course != b.Course {
+			return a.Course < b.Course
+		}
+		if a.Game != b.Game {
+			return a.Game < b.Game
+		}
+		return a.Layout < b.Layout
+	})
+	return results
+}
+
+// writeResults writes the results in the given format (table, json or csv)
+func writeResults(w io.Writer, format string, results []searchResult) error {
+	switch format {
+	case "json":
+		if results == nil {
+			results = []searchResult{} // "[]" rather than "null"
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"date", "time", "course", "game", "layout", "spots", "fees", "booking_url"})
+		for _, r := range results {
+			_ = cw.Write([]string{r.Date, r.Time, r.Course, r.Game, r.Layout, strconv.Itoa(r.Spots), formatFees(r.Fees), r.BookingURL})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case "table":
+		if len(results) == 0 {
+			_, err := fmt.Fprintln(w, "No tee times found.")
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "DATE\tTIME\tCOURSE\tGAME\tLAYOUT\tSPOTS\tFEES")
+		for _, r := range results {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n", r.Date, r.Time, r.Course, r.Game, r.Layout, r.Spots, formatFees(r.Fees))
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sampleResults = []searchResult{
+	{
+		Date: "2025-09-27", Time: "07:03", Course: "North", Game: "18 Holes", Layout: "Main", Spots: 4,
+		Fees:       []shared.Fee{{Amount: 30, Currency: "$", Label: "Weekday 18H"}},
+		BookingURL: "https://north.test/teetime/0703",
+	},
+	{Date: "2025-09-27", Time: "14:30", Course: "South, East", Game: "9 Holes", Layout: "Back 9", Spots: 2},
+}
+
+func TestWriteResults(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeResults(&buf, "json", sampleResults))
+
+		var got []searchResult
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, sampleResults, got)
+		assert.Contains(t, buf.String(), `"booking_url": "https://north.test/teetime/0703"`)
+	})
+
+	t.Run("Empty JSON is an array", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeResults(&buf, "json", nil))
+		assert.Equal(t, "[]\n", buf.String())
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeResults(&buf, "csv", sampleResults))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, "date,time,course,game,layout,spots,fees,booking_url", lines[0])
+		assert.Equal(t, "2025-09-27,07:03,North,18 Holes,Main,4,$30.00 Weekday 18H,https://north.test/teetime/0703", lines[1])
+		assert.Equal(t, `2025-09-27,14:30,"South, East",9 Holes,Back 9,2,,`, lines[2], "commas in names should be quoted")
+	})
+
+	t.Run("Table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeResults(&buf, "table", sampleResults))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 3)
+		assert.True(t, strings.HasPrefix(lines[0], "DATE"))
+		assert.Contains(t, lines[1], "North")
+		assert.Contains(t, lines[1], "$30.00 Weekday 18H")
+	})
+
+	t.Run("Empty table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeResults(&buf, "table", nil))
+		assert.Equal(t, "No tee times found.\n", buf.String())
+	})
+}
+
+func TestSearchExitCode(t *testing.T) {
+	assert.Equal(t, exitFound, searchExitCode(3, 0))
+	assert.Equal(t, exitFound, searchExitCode(3, 1), "results still count when some courses failed")
+	assert.Equal(t, exitNone, searchExitCode(0, 0))
+	assert.Equal(t, exitErrors, searchExitCode(0, 1))
+}
+
+func TestRunSearch(t *testing.T) {
+	day := time.Now().AddDate(0, 0, 2)
+	dayISO := day.Format("2006-01-02")
+
+	stubCalendars = map[string]map[string]map[string]string{
+		"https://north.test": {dayISO: {"18 Holes": "https://north.test/18"}},
+		"https://south.test": {dayISO: {"9 Holes": "https://south.test/9"}},
+	}
+	stubTimes = map[string]map[string][]shared.TeeTimeSlot{
+		"https://north.test/18": {"Main": {
+			{Time: "07:03 am", AvailableSpots: 4},
+			{Time: "09:30 am", AvailableSpots: 1},
+		}},
+		"https://south.test/9": {"Back 9": {{Time: "2:30PM", AvailableSpots: 2}}},
+	}
+	defer func() {
+		stubCalendars = map[string]map[string]map[string]string{}
+		stubTimes = map[string]map[string][]shared.TeeTimeSlot{}
+	}()
+
+	tmpConfig := filepath.Join(t.TempDir(), "config.txt")
+	content := "North,https://north.test,stub,false\nSouth,https://south.test,stub,false\nBroken,https://broken.test,golfnow,false\nHidden,https://hidden.test,stub,true\n"
+	require.NoError(t, os.WriteFile(tmpConfig, []byte(content), 0644))
+
+	// Save and restore globals
+	origPath, origDate, origTime, origSpots, origCourses, origFormat := configPath, specifiedDate, specifiedTime, specifiedSpots, courseList, outputFormat
+	defer func() {
+		configPath, specifiedDate, specifiedTime, specifiedSpots, courseList, outputFormat = origPath, origDate, origTime, origSpots, origCourses, origFormat
+	}()
+	configPath = tmpConfig
+	specifiedDate = day.Format("02-01-2006")
+	specifiedTime = ""
+	outputFormat = "json"
+
+	t.Run("Found", func(t *testing.T) {
+		specifiedSpots, courseList = 2, []string{"north", "south"}
+
+		var out, errOut bytes.Buffer
+		code := runSearch(context.Background(), &out, &errOut)
+		assert.Equal(t, exitFound, code)
+		assert.Empty(t, errOut.String())
+
+		var got []searchResult
+		require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+		require.Len(t, got, 2, "the 1 spot tee time should be filtered out")
+		assert.Equal(t, searchResult{Date: dayISO, Time: "07:03", Course: "North", Game: "18 Holes", Layout: "Main", Spots: 4}, got[0])
+		assert.Equal(t, "14:30", got[1].Time)
+	})
+
+	t.Run("None found", func(t *testing.T) {
+		specifiedSpots, courseList = 4, []string{"South"}
+
+		var out, errOut bytes.Buffer
+		assert.Equal(t, exitNone, runSearch(context.Background(), &out, &errOut))
+		assert.Equal(t, "[]\n", out.String())
+	})
+
+	t.Run("Failed course", func(t *testing.T) {
+		specifiedSpots, courseList = 4, []string{"South", "Broken"}
+
+		var out, errOut bytes.Buffer
+		assert.Equal(t, exitErrors, runSearch(context.Background(), &out, &errOut))
+		assert.Contains(t, errOut.String(), "1 course(s) couldn't be searched")
+		assert.Contains(t, errOut.String(), "Broken  unknown website type 'golfnow'")
+		assert.Equal(t, "[]\n", out.String(), "errors shouldn't end up in the results")
+	})
+
+	t.Run("Unknown course", func(t *testing.T) {
+		specifiedSpots, courseList = 0, []string{"Nowhere"}
+
+		var out, errOut bytes.Buffer
+		assert.Equal(t, exitErrors, runSearch(context.Background(), &out, &errOut))
+		assert.Contains(t, errOut.String(), "does not exist in config")
+		assert.Empty(t, out.String())
+	})
+}
@@ -6,6 +6,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -14,20 +15,27 @@ import (
 )
 
 type spinModel struct {
-	sp  spinner.Model
-	msg string
+	sp     spinner.Model
+	msg    string
+	cancel context.CancelFunc // stops the work on ctrl+c
 }
 
-func newSpinnerModel(msg string) spinModel {
+func newSpinnerModel(msg string, cancel context.CancelFunc) spinModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
-	return spinModel{sp: s, msg: msg}
+	return spinModel{sp: s, msg: msg, cancel: cancel}
 }
 
 func (m spinModel) Init() tea.Cmd { return m.sp.Tick }
 
 func (m spinModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok && key.String() == "ctrl+c" && m.cancel != nil {
+		m.cancel()
+		m.msg = "Cancelling..."
+		return m, nil
+	}
+
 	var cmd tea.Cmd
 	m.sp, cmd = m.sp.Update(msg)
 	return m, cmd
@@ -8,18 +8,28 @@ package cmd
 import (
 	"strings"
 
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
+
 	"github.com/charmbracelet/bubbles/paginator"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 const slotsPerPage = 18 // rows per page
 
+// one row in the pager: either a layout heading or a bookable tee time
+type pagerLine struct {
+	text string              // fully-rendered “07:03 am: 4 spots available – $30.00 9 Holes” string
+	slot *shared.TeeTimeSlot // nil for layout headings
+}
+
 type pagerModel struct {
-	lines     []string // fully-rendered “07:03 am – 4 spots” strings
+	lines     []pagerLine
+	cursor    int // index into lines, always on a tee time
+	chosen    *shared.TeeTimeSlot
 	paginator paginator.Model
 }
 
-func newPagerModel(lines []string) pagerModel {
+func newPagerModel(lines []pagerLine) pagerModel {
 	p := paginator.New()
 	p.Type = paginator.Dots // slick “•••” footer
 	p.PerPage = slotsPerPage
@@ -27,7 +37,27 @@ func newPagerModel(lines []string) pagerModel {
 	p.ActiveDot = "●"
 	p.SetTotalPages(len(lines))
 
-	return pagerModel{lines: lines, paginator: p}
+	m := pagerModel{lines: lines, paginator: p}
+	m.cursor = m.nextSlot(-1, +1)
+	return m
+}
+
+// helper: index of the next tee time from `from` in direction dir, or `from`
+// if there isn't one
+func (m pagerModel) nextSlot(from, dir int) int {
+	for i := from + dir; i >= 0 && i < len(m.lines); i += dir {
+		if m.lines[i].slot != nil {
+			return i
+		}
+	}
+	return from
+}
+
+// keep the paginator on whichever page the cursor is on
+func (m *pagerModel) syncPage() {
+	if m.cursor >= 0 {
+		m.paginator.Page = m.cursor / m.paginator.PerPage
+	}
 }
 
 func (m pagerModel) Init() tea.Cmd { return nil }
@@ -37,14 +67,36 @@ func (m pagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		switch msg.String() {
-		case "q", "esc", "ctrl+c", "enter":
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+
+		case "enter":
+			if m.cursor >= 0 && m.cursor < len(m.lines) {
+				m.chosen = m.lines[m.cursor].slot
+			}
 			return m, tea.Quit
+
+		case "up", "k":
+			m.cursor = m.nextSlot(m.cursor, -1)
+			m.syncPage()
+			return m, nil
+
+		case "down", "j":
+			m.cursor = m.nextSlot(m.cursor, +1)
+			m.syncPage()
+			return m, nil
 		}
 	}
 
 	// pass input to paginator (--, space, left/right, etc.)
 	var cmd tea.Cmd
 	m.paginator, cmd = m.paginator.Update(msg)
+
+	// if the page changed, move the cursor onto it
+	start, _ := m.paginator.GetSliceBounds(len(m.lines))
+	if m.cursor/m.paginator.PerPage != m.paginator.Page {
+		m.cursor = m.nextSlot(start-1, +1)
+	}
 	return m, cmd
 }
 
@@ -58,18 +110,22 @@ func (m pagerModel) View() string {
 
 	// slice for the current page
 	start, end := m.paginator.GetSliceBounds(len(m.lines))
-	for _, l := range m.lines[start:end] {
-
-		if strings.HasSuffix(l, ":") {
-			b.WriteString("  • " + strings.TrimSuffix(l, "\n") + "\n\n")
-		} else {
-			b.WriteString("    • " + strings.TrimSuffix(l, "\n") + "\n\n")
+	for i, l := range m.lines[start:end] {
+		text := strings.TrimSuffix(l.text, "\n")
+
+		switch {
+		case l.slot == nil:
+			b.WriteString("  • " + text + "\n\n")
+		case start+i == m.cursor:
+			b.WriteString(hoverStyle.Render("  > • "+text) + "\n\n")
+		default:
+			b.WriteString("    • " + text + "\n\n")
 		}
 	}
 
 	b.WriteString("  " + m.paginator.View())
 
-	help := controlStyle.Render("\n\n  h/l ←/→ page • q/enter: continue\n")
+	help := controlStyle.Render("\n\n  ↑/↓ j/k: move • h/l ←/→ page • enter: pick tee time • q: continue\n")
 	b.WriteString(help)
 
 	return b.String()
@@ -0,0 +1,272 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/config"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/provider"
+
+	"github.com/spf13/cobra"
+)
+
+// courseStatus is the outcome of checking one course's booking page
+type courseStatus int
+
+const (
+	statusOK courseStatus = iota
+	statusBadURL
+	statusWrongType
+	statusNoFeeGroups
+	statusUnreachable
+)
+
+func (s courseStatus) String() string {
+	switch s {
+	case statusOK:
+		return "OK"
+	case statusBadURL:
+		return "bad URL"
+	case statusWrongType:
+		return "wrong website type"
+	case statusNoFeeGroups:
+		return "no fee groups found"
+	case statusUnreachable:
+		return "unreachable"
+	}
+	return "unknown"
+}
+
+// courseCheck is what `config validate` found for a course
+type courseCheck struct {
+	status  courseStatus
+	detail  string
+	suggest string // the website type that can read the page, if it's the wrong one
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check every configured course's booking page can be read",
+	Long: `Validate fetches today's calendar for every course in the config using its
+website type's scraper, and reports whether it worked. Courses that fail can
+be blacklisted or fixed straight away when run in a terminal.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		var in io.Reader
+		if stdinIsTerminal() {
+			in = cmd.InOrStdin()
+		}
+		if !runValidate(ctx, in, cmd.OutOrStdout()) {
+			exitCode = 1
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(validateCmd)
+}
+
+// Helper function to tell if we can ask the user questions
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// runValidate checks every course and prints a table of the results. If in
+// isn't nil the user is asked what to do about each broken course and any
+// changes are saved. It reports whether every course is OK (or blacklisted)
+// by the end.
+func runValidate(ctx context.Context, in io.Reader, out io.Writer) bool {
+	cfg, err := config.Load(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(out, "No config file found. Please add courses using `TeeTimeFinder config`.")
+		return false
+	}
+	if err != nil {
+		fmt.Fprintf(out, "The config file has problems:\n%v\n", err)
+		return false
+	}
+	if len(cfg.Courses) == 0 {
+		fmt.Fprintln(out, "No courses found in the config.")
+		return true
+	}
+
+	f, err := newFetcher()
+	if err != nil {
+		fmt.Fprintf(out, "Failed to check courses: %v\n", err)
+		return false
+	}
+
+	checks := checkCourses(ctx, f, cfg.Courses)
+	printChecks(out, cfg.Courses, checks)
+
+	if in != nil {
+		if offerFixes(ctx, f, bufio.NewScanner(in), out, cfg, checks) {
+			if err := config.Save(configPath, cfg); err != nil {
+				fmt.Fprintf(out, "Failed to save updated config: %v\n", err)
+				return false
+			}
+			fmt.Fprintln(out, "Config updated!")
+		}
+	}
+
+	for i, c := range cfg.Courses {
+		if checks[i].status != statusOK && !c.Blacklisted {
+			return false
+		}
+	}
+	return true
+}
+
+// Helper function to check every course on the worker pool, the results
+// are in the same order as courses
+func checkCourses(ctx context.Context, f *fetch.Fetcher, courses []config.Course) []courseCheck {
+	byName := make(map[string]CourseConfig, len(courses))
+	index := make(map[string]int, len(courses))
+	for i, c := range courses {
+		byName[c.Name] = CourseConfig{URL: c.URL, WebsiteType: c.WebsiteType}
+		index[c.Name] = i
+	}
+
+	checks := make([]courseCheck, len(courses))
+	scrapeCourses(ctx, byName,
+		func(ctx context.Context, name string, _ CourseConfig) (courseCheck, error) {
+			return checkCourse(ctx, f, courses[index[name]]), nil
+		},
+		func(name string, check courseCheck, err error) {
+			if err != nil {
+				check = courseCheck{status: statusUnreachable, detail: failureReason(err)}
+			}
+			checks[index[name]] = check
+		})
+	return checks
+}
+
+// checkCourse loads today's games for a course and works out what's wrong
+// if that fails. A page its scraper can't read is checked against the
+// website type the URL looks like, to catch a course saved as the wrong one.
+func checkCourse(ctx context.Context, f *fetch.Fetcher, c config.Course) courseCheck {
+	if err := c.Validate(); err != nil {
+		return courseCheck{status: statusBadURL, detail: err.Error()}
+	}
+	detected, _ := provider.Detect(c.URL)
+
+	p, ok := provider.Lookup(c.WebsiteType)
+	if !ok {
+		check := courseCheck{status: statusWrongType, detail: fmt.Sprintf("unknown website type '%s'", c.WebsiteType)}
+		if detected != nil {
+			check.suggest = detected.Name()
+		}
+		return check
+	}
+
+	games, err := p.ScrapeDates(ctx, f, c.URL, time.Now())
+	if err == nil {
+		return courseCheck{status: statusOK, detail: fmt.Sprintf("%d game(s) bookable today", len(games))}
+	}
+
+	var (
+		status *fetch.StatusError
+		page   *fetch.PageError
+	)
+	switch {
+	case errors.As(err, &status) && status.StatusCode >= 400 && status.StatusCode < 500:
+		return courseCheck{status: statusBadURL, detail: failureReason(err)}
+	case errors.As(err, &page):
+		if detected != nil && !strings.EqualFold(detected.Name(), p.Name()) {
+			check := courseCheck{status: statusWrongType, detail: fmt.Sprintf("the URL looks like %s", detected.Name()), suggest: detected.Name()}
+			if _, err := detected.ScrapeDates(ctx, f, c.URL, time.Now()); err == nil {
+				check.detail = fmt.Sprintf("the page reads as %s", detected.Name())
+			}
+			return check
+		}
+		return courseCheck{status: statusNoFeeGroups, detail: failureReason(err)}
+	}
+	return courseCheck{status: statusUnreachable, detail: failureReason(err)}
+}
+
+// Helper function to print the results as a table in config order
+func printChecks(w io.Writer, courses []config.Course, checks []courseCheck) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, c := range courses {
+		name := c.Name
+		if c.Blacklisted {
+			name += " (blacklisted)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, checks[i].status, checks[i].detail)
+	}
+	tw.Flush()
+}
+
+// offerFixes asks what to do about each broken course that isn't already
+// blacklisted. It updates cfg and checks in place and reports whether
+// anything changed.
+func offerFixes(ctx context.Context, f *fetch.Fetcher, answers *bufio.Scanner, out io.Writer, cfg *config.Config, checks []courseCheck) bool {
+	ask := func(prompt string) string {
+		fmt.Fprint(out, prompt)
+		if !answers.Scan() {
+			return ""
+		}
+		return strings.TrimSpace(answers.Text())
+	}
+
+	changed := false
+	for i := range cfg.Courses {
+		c := &cfg.Courses[i]
+		if checks[i].status == statusOK || c.Blacklisted {
+			continue
+		}
+
+		fmt.Fprintf(out, "\n%s: %s (%s)\n", c.Name, checks[i].status, checks[i].detail)
+		switch strings.ToLower(ask("[f]ix, [b]lacklist or [s]kip? ")) {
+		case "b", "blacklist":
+			c.Blacklisted = true
+			changed = true
+			fmt.Fprintf(out, "Blacklisted %s\n", c.Name)
+
+		case "f", "fix":
+			fixed := *c
+			if checks[i].suggest != "" && strings.ToLower(ask(fmt.Sprintf("Change the website type to %s? [Y/n] ", checks[i].suggest))) != "n" {
+				fixed.WebsiteType = checks[i].suggest
+			} else {
+				newURL := ask("New URL (blank to keep it): ")
+				if newURL == "" {
+					continue
+				}
+				fixed.URL = newURL
+				if p, courseURL, ok := provider.Normalise(newURL); ok {
+					fixed.URL = courseURL
+					fixed.WebsiteType = p.Name()
+				}
+			}
+
+			check := checkCourse(ctx, f, fixed)
+			if check.status != statusOK {
+				fmt.Fprintf(out, "Still %s (%s), %s wasn't changed\n", check.status, check.detail, c.Name)
+				continue
+			}
+			*c = fixed
+			checks[i] = check
+			changed = true
+			fmt.Fprintf(out, "Fixed %s\n", c.Name)
+		}
+	}
+	return changed
+}
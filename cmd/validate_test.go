@@ -0,0 +1,91 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/config"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fakesite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Helper function to set up a fake site with one course for each thing
+// `config validate` can report
+func withValidateSite(t *testing.T) {
+	someDay := map[string]map[string][]fakesite.TeeTime{"2025-10-18": {"18 Holes": nil}}
+	withFakeSite(t,
+		fakeCourse{"Good Golf Club", fakesite.Course{Platform: fakesite.MiClub, ID: "good", Times: someDay}},
+		fakeCourse{"Mislabelled Golf Course", fakesite.Course{Platform: fakesite.Quick18, ID: "springs"}},
+		fakeCourse{"Gone Golf Club", fakesite.Course{Platform: fakesite.MiClub, ID: "gone", Status: http.StatusNotFound}},
+		fakeCourse{"Down Golf Club", fakesite.Course{Platform: fakesite.MiClub, ID: "down", Status: http.StatusServiceUnavailable}},
+		fakeCourse{"Empty Golf Club", fakesite.Course{Platform: fakesite.MiClub, ID: "empty"}},
+	)
+
+	// Save the Quick18 course as the wrong type
+	cfg, err := config.Load(configPath)
+	require.NoError(t, err)
+	cfg.Courses[1].WebsiteType = "miclub"
+	require.NoError(t, config.Save(configPath, cfg))
+}
+
+func TestConfigValidate(t *testing.T) {
+	withValidateSite(t)
+
+	var out bytes.Buffer
+	ok := runValidate(context.Background(), nil, &out)
+	assert.False(t, ok, "broken courses fail the check")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 5, out.String())
+	assert.Regexp(t, `^Good Golf Club\s+OK\s`, lines[0])
+	assert.Regexp(t, `^Mislabelled Golf Course\s+wrong website type\s+the page reads as quick18`, lines[1])
+	assert.Regexp(t, `^Gone Golf Club\s+bad URL\s`, lines[2])
+	assert.Regexp(t, `^Down Golf Club\s+unreachable\s`, lines[3])
+	assert.Regexp(t, `^Empty Golf Club\s+no fee groups found\s`, lines[4])
+}
+
+func TestConfigValidateFixes(t *testing.T) {
+	withValidateSite(t)
+
+	cfg, err := config.Load(configPath)
+	require.NoError(t, err)
+	goodURL := cfg.Courses[0].URL
+
+	answers := strings.Join([]string{
+		"f", "", // Mislabelled: fix, take the suggested type
+		"b",          // Gone: blacklist
+		"s",          // Down: skip
+		"f", goodURL, // Empty: fix with a new URL
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	ok := runValidate(context.Background(), strings.NewReader(answers), &out)
+	assert.False(t, ok, "the skipped course is still broken")
+	assert.Contains(t, out.String(), "Fixed Mislabelled Golf Course")
+	assert.Contains(t, out.String(), "Blacklisted Gone Golf Club")
+	assert.Contains(t, out.String(), "Fixed Empty Golf Club")
+	assert.Contains(t, out.String(), "Config updated!")
+
+	cfg, err = config.Load(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "quick18", cfg.Courses[1].WebsiteType)
+	assert.True(t, cfg.Courses[2].Blacklisted)
+	assert.False(t, cfg.Courses[3].Blacklisted)
+	assert.Equal(t, goodURL, cfg.Courses[4].URL)
+
+	// With the skipped course blacklisted everything passes
+	cfg.Courses[3].Blacklisted = true
+	require.NoError(t, config.Save(configPath, cfg))
+	out.Reset()
+	assert.True(t, runValidate(context.Background(), nil, &out), out.String())
+}
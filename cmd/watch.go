@@ -0,0 +1,218 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Every check fetches each course's pages again, so don't let the interval
+// get short enough to bother the booking sites
+const minWatchInterval = time.Minute
+
+var (
+	watchInterval time.Duration
+	watchJitter   time.Duration
+	watchNotify   string
+	keepWatching  bool
+)
+
+// Overridden in tests so they don't have to wait
+var watchSleep = func(ctx context.Context, d time.Duration) {
+	select {
+	case <-time.After(d):
+	case <-ctx.Done():
+	}
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Keep searching until a matching tee time comes up",
+	Long: `Watch repeats the search from the -d/-t/-s/-c flags (and --from/--to/--days)
+every --interval, plus or minus a random --jitter, until a tee time matches.
+
+Only tee times that haven't been seen in an earlier check are reported. When
+one turns up it is printed, the terminal bell rings and the --notify command
+(if any) is run with the new tee times on stdin. Watch stops after the first
+find unless --keep-watching is set, and gives up once the dates have passed.
+
+Exit codes are the same as for search.`,
+	Example: `  TeeTimeFinder watch -d 22-02-2025 -t 08:00 -s 4
+  TeeTimeFinder watch --from 22-02-2025 --to 23-02-2025 -s 4 --interval 10m --notify 'notify-send "Tee time found"'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		exitCode = runWatch(ctx, cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 5*time.Minute, fmt.Sprintf("Time between searches (minimum %s)", minWatchInterval))
+	watchCmd.Flags().DurationVar(&watchJitter, "jitter", 30*time.Second, "Randomly shift each interval by up to this much")
+	watchCmd.Flags().StringVar(&watchNotify, "notify", "", "Shell command to run when new tee times are found (they are passed on stdin)")
+	watchCmd.Flags().BoolVar(&keepWatching, "keep-watching", false, "Keep watching for more tee times after the first find")
+	watchCmd.Flags().StringVarP(&outputFormat, "format", "f", "table", "Output format: table, json or csv")
+
+	watchCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "json", "csv"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// runWatch polls until new tee times turn up (or the dates pass, or ctx is
+// cancelled) and returns the exit code. Tee times go to out, progress and
+// errors to errOut.
+func runWatch(ctx context.Context, out, errOut io.Writer) int {
+	format, err := checkOutputFormat()
+	if err != nil {
+		fmt.Fprintf(errOut, "Error: %v\n", err)
+		return exitErrors
+	}
+	if offlineMode {
+		fmt.Fprintln(errOut, "Error: watch needs to go online, it can't be used with --offline")
+		return exitErrors
+	}
+	if watchInterval < minWatchInterval {
+		fmt.Fprintf(errOut, "Error: --interval must be at least %s\n", minWatchInterval)
+		return exitErrors
+	}
+
+	params, err := searchParamsFromFlags()
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitErrors
+	}
+
+	// searching is pointless once the last day is over
+	lastDay := params.dates[len(params.dates)-1]
+	deadline := time.Date(lastDay.Year(), lastDay.Month(), lastDay.Day()+1, 0, 0, 0, 0, time.Local)
+
+	seen := make(map[string]bool)
+	found := 0
+	for check := 1; ; check++ {
+		// a new fetcher each check, and cached pages are only saved, never
+		// reused, otherwise it would hand back the old pages
+		f, err := newFetcher()
+		if err != nil {
+			fmt.Fprintf(errOut, "Error: %v\n", err)
+			return exitErrors
+		}
+		if f.Cache != nil {
+			f.Cache.TTL = 0
+		}
+		results, failures := searchTeeTimes(ctx, f, params)
+		if ctx.Err() != nil {
+			return stopWatching(errOut, found)
+		}
+		printFailures(errOut, failures)
+
+		fresh := unseenResults(seen, results)
+		stamp := time.Now().Format("15:04:05")
+
+		if len(fresh) > 0 {
+			found += len(fresh)
+			fmt.Fprintf(errOut, "[%s] Check %d: %d new tee time(s)\a\n", stamp, check, len(fresh))
+			if err := writeResults(out, format, fresh); err != nil {
+				fmt.Fprintf(errOut, "Error writing results: %v\n", err)
+				return exitErrors
+			}
+			if err := runNotify(watchNotify, fresh); err != nil {
+				fmt.Fprintf(errOut, "Notify command failed: %v\n", err)
+			}
+			if !keepWatching {
+				return exitFound
+			}
+		} else {
+			fmt.Fprintf(errOut, "[%s] Check %d: nothing new\n", stamp, check)
+		}
+
+		wait := nextWatchDelay(watchInterval, watchJitter, rand.Float64())
+		if time.Now().Add(wait).After(deadline) {
+			fmt.Fprintln(errOut, "The search dates have passed, stopping.")
+			if found > 0 {
+				return exitFound
+			}
+			return exitNone
+		}
+
+		fmt.Fprintf(errOut, "Checking again at %s\n", time.Now().Add(wait).Format("15:04:05"))
+		watchSleep(ctx, wait)
+		if ctx.Err() != nil {
+			return stopWatching(errOut, found)
+		}
+	}
+}
+
+// Helper function to pick the exit code when the user stops watching
+func stopWatching(errOut io.Writer, found int) int {
+	fmt.Fprintln(errOut, "Stopped watching.")
+	if found > 0 {
+		return exitFound
+	}
+	return exitNone
+}
+
+// unseenResults returns the results not reported by an earlier check and
+// marks them as seen
+func unseenResults(seen map[string]bool, results []searchResult) []searchResult {
+	var fresh []searchResult
+	for _, r := range results {
+		key := strings.Join([]string{r.Date, r.Course, r.Game, r.Layout, r.Time}, "|")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		fresh = append(fresh, r)
+	}
+	return fresh
+}
+
+// nextWatchDelay shifts the interval by up to ±jitter (r is a random number
+// in [0, 1)) so checks don't land on the sites at exactly the same moment.
+// Jitter is capped at half the interval.
+func nextWatchDelay(interval, jitter time.Duration, r float64) time.Duration {
+	if jitter > interval/2 {
+		jitter = interval / 2
+	}
+	if jitter <= 0 {
+		return interval
+	}
+	return interval - jitter + time.Duration(r*float64(2*jitter))
+}
+
+// runNotify runs the user's --notify command through the shell with the new
+// tee times written to its stdin as a table
+func runNotify(command string, results []searchResult) error {
+	if strings.TrimSpace(command) == "" {
+		return nil
+	}
+
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", command)
+	} else {
+		c = exec.Command("sh", "-c", command)
+	}
+
+	var table strings.Builder
+	_ = writeResults(&table, "table", results)
+	c.Stdin = strings.NewReader(table.String())
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	return c.Run()
+}
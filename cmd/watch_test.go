@@ -0,0 +1,139 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnseenResults(t *testing.T) {
+	seen := make(map[string]bool)
+
+	first := unseenResults(seen, sampleResults)
+	assert.Equal(t, sampleResults, first, "everything is new on the first check")
+
+	again := unseenResults(seen, sampleResults)
+	assert.Empty(t, again, "the same tee times shouldn't be reported twice")
+
+	later := sampleResults[0]
+	later.Time = "07:11"
+	assert.Equal(t, []searchResult{later}, unseenResults(seen, append(sampleResults, later)))
+}
+
+func TestNextWatchDelay(t *testing.T) {
+	assert.Equal(t, 4*time.Minute, nextWatchDelay(5*time.Minute, time.Minute, 0))
+	assert.Equal(t, 5*time.Minute, nextWatchDelay(5*time.Minute, time.Minute, 0.5))
+	assert.Equal(t, 6*time.Minute, nextWatchDelay(5*time.Minute, time.Minute, 1))
+	assert.Equal(t, 5*time.Minute, nextWatchDelay(5*time.Minute, 0, 0.9), "no jitter means a fixed interval")
+	assert.Equal(t, time.Minute, nextWatchDelay(2*time.Minute, time.Hour, 0), "jitter is capped at half the interval")
+}
+
+func TestRunWatch(t *testing.T) {
+	day := time.Now().AddDate(0, 0, 2)
+	dayISO := day.Format("2006-01-02")
+
+	stubCalendars = map[string]map[string]map[string]string{
+		"https://north.test": {dayISO: {"18 Holes": "https://north.test/18"}},
+	}
+	stubTimes = map[string]map[string][]shared.TeeTimeSlot{
+		"https://north.test/18": {"Main": {{Time: "07:03 am", AvailableSpots: 2}}},
+	}
+
+	tmpConfig := filepath.Join(t.TempDir(), "config.txt")
+	require.NoError(t, os.WriteFile(tmpConfig, []byte("North,https://north.test,stub,false\n"), 0644))
+
+	// Save and restore globals
+	origPath, origDate, origTime, origSpots, origCourses, origFormat := configPath, specifiedDate, specifiedTime, specifiedSpots, courseList, outputFormat
+	origInterval, origSleep, origKeep := watchInterval, watchSleep, keepWatching
+	defer func() {
+		configPath, specifiedDate, specifiedTime, specifiedSpots, courseList, outputFormat = origPath, origDate, origTime, origSpots, origCourses, origFormat
+		watchInterval, watchSleep, keepWatching = origInterval, origSleep, origKeep
+		stubCalendars = map[string]map[string]map[string]string{}
+		stubTimes = map[string]map[string][]shared.TeeTimeSlot{}
+	}()
+	configPath = tmpConfig
+	specifiedDate = day.Format("02-01-2006")
+	specifiedTime, courseList = "", nil
+	specifiedSpots = 4
+	outputFormat = "json"
+	watchInterval = 5 * time.Minute
+	keepWatching = false
+
+	// A 4 player tee time opens up after the first check
+	sleeps := 0
+	watchSleep = func(_ context.Context, d time.Duration) {
+		sleeps++
+		assert.GreaterOrEqual(t, d, watchInterval-watchJitter)
+		stubTimes["https://north.test/18"]["Main"] = append(stubTimes["https://north.test/18"]["Main"],
+			shared.TeeTimeSlot{Time: "07:11 am", AvailableSpots: 4})
+	}
+
+	var out, errOut bytes.Buffer
+	code := runWatch(context.Background(), &out, &errOut)
+
+	assert.Equal(t, exitFound, code)
+	assert.Equal(t, 1, sleeps, "watch should stop after the first find")
+	assert.Contains(t, errOut.String(), "Check 1: nothing new")
+	assert.Contains(t, errOut.String(), "Check 2: 1 new tee time(s)")
+
+	var got []searchResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	require.Len(t, got, 1)
+	assert.Equal(t, "07:11", got[0].Time)
+}
+
+func TestRunWatchRejectsShortInterval(t *testing.T) {
+	orig := watchInterval
+	defer func() { watchInterval = orig }()
+	watchInterval = 10 * time.Second
+
+	var out, errOut bytes.Buffer
+	assert.Equal(t, exitErrors, runWatch(context.Background(), &out, &errOut))
+	assert.Contains(t, errOut.String(), "--interval must be at least")
+}
+
+func TestRunWatchCancel(t *testing.T) {
+	day := time.Now().AddDate(0, 0, 2)
+
+	tmpConfig := filepath.Join(t.TempDir(), "config.txt")
+	require.NoError(t, os.WriteFile(tmpConfig, []byte("North,https://north.test,stub,false\n"), 0644))
+
+	origPath, origDate, origTime, origSpots, origCourses, origFormat := configPath, specifiedDate, specifiedTime, specifiedSpots, courseList, outputFormat
+	origInterval, origSleep := watchInterval, watchSleep
+	defer func() {
+		configPath, specifiedDate, specifiedTime, specifiedSpots, courseList, outputFormat = origPath, origDate, origTime, origSpots, origCourses, origFormat
+		watchInterval, watchSleep = origInterval, origSleep
+	}()
+	configPath = tmpConfig
+	specifiedDate = day.Format("02-01-2006")
+	specifiedTime, courseList, specifiedSpots = "", nil, 0
+	outputFormat = "table"
+	watchInterval = 5 * time.Minute
+
+	// ctrl+c while waiting for the next check
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watchSleep = func(ctx context.Context, _ time.Duration) {
+		cancel()
+		<-ctx.Done()
+	}
+
+	var out, errOut bytes.Buffer
+	assert.Equal(t, exitNone, runWatch(ctx, &out, &errOut))
+	assert.Contains(t, errOut.String(), "Check 1: nothing new")
+	assert.Contains(t, errOut.String(), "Stopped watching.")
+}
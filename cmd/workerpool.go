@@ -0,0 +1,140 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultWorkers        = 4
+	defaultPerHostWorkers = 2 // same as fetch.DefaultParallelism
+	defaultCourseTimeout  = 60 * time.Second
+)
+
+var scrapeWorkers int
+var perHostWorkers int
+var courseTimeout time.Duration
+
+// courseResult is what one worker hands back for a course
+type courseResult[T any] struct {
+	name   string
+	result T
+	err    error
+}
+
+// scrapeCourses runs scrape for every course, at most scrapeWorkers at once
+// and at most perHostWorkers from the same host. collect is called as each
+// course finishes, always from the calling goroutine, so it can merge
+// results and send progress without locking.
+//
+// The fetcher already limits requests per host to keep the sites happy, but
+// a course that starts on a busy host would sit waiting for it while holding
+// a worker. So a course is only started once its host has room, and the
+// workers go to courses on other hosts in the meantime.
+//
+// Each course gets courseTimeout to finish, after which it fails with a
+// courseTimeoutError. Once ctx is cancelled the courses still waiting are
+// collected straight away with ctx's error.
+func scrapeCourses[T any](ctx context.Context, courses map[string]CourseConfig, scrape func(ctx context.Context, name string, cfg CourseConfig) (T, error), collect func(name string, result T, err error)) {
+	workers := scrapeWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	perHost := perHostWorkers
+	if perHost < 1 {
+		perHost = 1
+	}
+
+	// sorted so the order courses start in doesn't change between runs
+	waiting := make([]string, 0, len(courses))
+	for name := range courses {
+		waiting = append(waiting, name)
+	}
+	sort.Strings(waiting)
+
+	results := make(chan courseResult[T])
+	running := 0
+	busy := make(map[string]int) // courses running on each host
+
+	// Helper function to start the first waiting courses whose hosts have
+	// room, until every worker is busy
+	startMore := func() {
+		if err := ctx.Err(); err != nil {
+			for _, name := range waiting {
+				var zero T
+				collect(name, zero, err)
+			}
+			waiting = nil
+			return
+		}
+
+		for i := 0; i < len(waiting) && running < workers; {
+			name := waiting[i]
+			cfg := courses[name]
+			host := courseHost(cfg.URL)
+			if busy[host] >= perHost {
+				i++
+				continue
+			}
+			waiting = append(waiting[:i], waiting[i+1:]...)
+			busy[host]++
+			running++
+
+			go func() {
+				res, err := scrapeOne(ctx, name, cfg, scrape)
+				results <- courseResult[T]{name: name, result: res, err: err}
+			}()
+		}
+	}
+
+	startMore()
+	for running > 0 {
+		r := <-results
+		running--
+		busy[courseHost(courses[r.name].URL)]--
+		collect(r.name, r.result, r.err)
+		startMore()
+	}
+}
+
+// Helper function to scrape one course within courseTimeout. A timeout is
+// reported as a courseTimeoutError so it can be told apart from the whole
+// search being cancelled.
+func scrapeOne[T any](ctx context.Context, name string, cfg CourseConfig, scrape func(ctx context.Context, name string, cfg CourseConfig) (T, error)) (T, error) {
+	if err := ctx.Err(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	courseCtx := ctx
+	if courseTimeout > 0 {
+		var cancel context.CancelFunc
+		courseCtx, cancel = context.WithTimeout(ctx, courseTimeout)
+		defer cancel()
+	}
+
+	res, err := scrape(courseCtx, name, cfg)
+	if err != nil && ctx.Err() == nil && errors.Is(courseCtx.Err(), context.DeadlineExceeded) {
+		return res, courseTimeoutError{after: courseTimeout}
+	}
+	return res, err
+}
+
+// Helper function to get the host a course URL points at, used to group
+// courses for the per-host limit
+func courseHost(courseURL string) string {
+	u, err := url.Parse(strings.TrimSpace(courseURL))
+	if err != nil || u.Host == "" {
+		return strings.ToLower(courseURL)
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrapeCourses(t *testing.T) {
+	origWorkers, origPerHost := scrapeWorkers, perHostWorkers
+	defer func() { scrapeWorkers, perHostWorkers = origWorkers, origPerHost }()
+	scrapeWorkers, perHostWorkers = 3, 1
+
+	// four courses on one shared host plus four on their own hosts
+	courses := make(map[string]CourseConfig)
+	for i := 0; i < 4; i++ {
+		courses[fmt.Sprintf("Shared %d", i)] = CourseConfig{URL: fmt.Sprintf("https://bookings.shared.test/course/%d", i)}
+		courses[fmt.Sprintf("Solo %d", i)] = CourseConfig{URL: fmt.Sprintf("https://solo%d.test/", i)}
+	}
+
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+	activeHosts, maxPerHost := map[string]int{}, 0
+
+	collected := map[string]string{}
+	var failed []string
+
+	scrapeCourses(context.Background(), courses,
+		func(_ context.Context, name string, cfg CourseConfig) (string, error) {
+			host := courseHost(cfg.URL)
+
+			mu.Lock()
+			active++
+			activeHosts[host]++
+			if active > maxActive {
+				maxActive = active
+			}
+			if activeHosts[host] > maxPerHost {
+				maxPerHost = activeHosts[host]
+			}
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			active--
+			activeHosts[host]--
+			mu.Unlock()
+
+			if name == "Solo 3" {
+				return "", errors.New("boom")
+			}
+			return "scraped " + name, nil
+		},
+		func(name, result string, err error) {
+			if err != nil {
+				failed = append(failed, name)
+				return
+			}
+			collected[name] = result
+		},
+	)
+
+	assert.Len(t, collected, 7, "every other course should be collected once")
+	assert.Equal(t, "scraped Shared 2", collected["Shared 2"])
+	assert.Equal(t, []string{"Solo 3"}, failed)
+	assert.LessOrEqual(t, maxActive, 3, "no more than --workers courses at once")
+	assert.Greater(t, maxActive, 1, "courses should be scraped concurrently")
+	assert.Equal(t, 1, maxPerHost, "no more than --per-host courses on one host at once")
+}
+
+func TestScrapeCoursesSkipsBusyHosts(t *testing.T) {
+	origWorkers, origPerHost := scrapeWorkers, perHostWorkers
+	defer func() { scrapeWorkers, perHostWorkers = origWorkers, origPerHost }()
+	scrapeWorkers, perHostWorkers = 2, 1
+
+	// "A 2" comes before "B" but has to wait for "A 1", so the second worker
+	// should go to "B" rather than sit waiting for the shared host
+	courses := map[string]CourseConfig{
+		"A 1": {URL: "https://a.test/1"},
+		"A 2": {URL: "https://a.test/2"},
+		"B":   {URL: "https://b.test/"},
+	}
+
+	bStarted := make(chan struct{})
+	var mu sync.Mutex
+	var started []string
+	scrapeCourses(context.Background(), courses,
+		func(_ context.Context, name string, cfg CourseConfig) (string, error) {
+			mu.Lock()
+			started = append(started, name)
+			mu.Unlock()
+
+			switch name {
+			case "A 1":
+				select {
+				case <-bStarted:
+				case <-time.After(2 * time.Second):
+					return "", errors.New("B never started")
+				}
+			case "B":
+				close(bStarted)
+			}
+			return name, nil
+		},
+		func(name, _ string, err error) {
+			assert.NoError(t, err, name)
+		},
+	)
+
+	assert.Len(t, started, 3)
+	assert.Equal(t, "A 2", started[len(started)-1], "A 2 waits for its host")
+}
+
+func TestCourseHost(t *testing.T) {
+	assert.Equal(t, "springs.quick18.com", courseHost("https://Springs.Quick18.com/teetimes/searchmatrix"))
+	assert.Equal(t, "bookings.collierparkgolf.com.au", courseHost(" https://bookings.collierparkgolf.com.au:443/guests "))
+	assert.Equal(t, "not a url", courseHost("Not a URL"))
+}
+
+func TestScrapeCoursesTimeout(t *testing.T) {
+	origWorkers, origPerHost, origTimeout := scrapeWorkers, perHostWorkers, courseTimeout
+	defer func() { scrapeWorkers, perHostWorkers, courseTimeout = origWorkers, origPerHost, origTimeout }()
+	scrapeWorkers, perHostWorkers, courseTimeout = 2, 2, 50*time.Millisecond
+
+	courses := map[string]CourseConfig{
+		"Fast": {URL: "https://fast.test/"},
+		"Hung": {URL: "https://hung.test/"},
+	}
+
+	errs := map[string]error{}
+	scrapeCourses(context.Background(), courses,
+		func(ctx context.Context, name string, cfg CourseConfig) (string, error) {
+			if name == "Hung" {
+				<-ctx.Done()
+				return "", ctx.Err()
+			}
+			return name, nil
+		},
+		func(name, _ string, err error) {
+			errs[name] = err
+		},
+	)
+
+	assert.NoError(t, errs["Fast"])
+	var timeout courseTimeoutError
+	assert.ErrorAs(t, errs["Hung"], &timeout, "a course past --timeout should be reported as timed out")
+	assert.Equal(t, "timed out after 50ms", errs["Hung"].Error())
+}
+
+func TestScrapeCoursesCancel(t *testing.T) {
+	origWorkers, origPerHost, origTimeout := scrapeWorkers, perHostWorkers, courseTimeout
+	defer func() { scrapeWorkers, perHostWorkers, courseTimeout = origWorkers, origPerHost, origTimeout }()
+	scrapeWorkers, perHostWorkers, courseTimeout = 1, 1, 0
+
+	courses := make(map[string]CourseConfig)
+	for i := 0; i < 5; i++ {
+		courses[fmt.Sprintf("Course %d", i)] = CourseConfig{URL: fmt.Sprintf("https://c%d.test/", i)}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scraped := 0
+	collected := 0
+	scrapeCourses(ctx, courses,
+		func(ctx context.Context, name string, cfg CourseConfig) (string, error) {
+			scraped++
+			cancel() // the user hits ctrl+c during the first course
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+		func(name, _ string, err error) {
+			collected++
+			assert.ErrorIs(t, err, context.Canceled, "%s should be cancelled, not timed out", name)
+		},
+	)
+
+	assert.Equal(t, 1, scraped, "no more courses should start once cancelled")
+	assert.Equal(t, 5, collected, "every course is still collected so progress finishes")
+}
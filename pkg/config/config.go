@@ -0,0 +1,307 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+// Package config reads and writes the TeeTimeFinder config file.
+//
+// The config is a YAML file with a schema version, optional defaults for the
+// search flags and a list of courses:
+//
+//	version: 1
+//	defaults:
+//	  spots: 2
+//	courses:
+//	  - name: Hamersley Golf Course
+//	    url: https://hamersley.quick18.com/teetimes/searchmatrix
+//	    type: quick18
+//	    aliases: [hamo]
+//	    tags: [north]
+//	    notes: Back 9 is shut on Mondays
+//
+// The old comma separated config.txt (name,URL,website type[,blacklisted])
+// can still be read, and Migrate moves it over to the YAML file. Every
+// command loads the config through Load, so they all agree on what it
+// contains, and problems are reported with the line they are on.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Version is the schema version written to new config files
+const Version = 1
+
+// LegacyFile is the name of the old comma separated config file
+const LegacyFile = "config.txt"
+
+// Course is one golf course in the config file
+type Course struct {
+	Name        string   `yaml:"name"`
+	URL         string   `yaml:"url"`
+	WebsiteType string   `yaml:"type"`                  // provider name, e.g. "miclub"
+	Aliases     []string `yaml:"aliases,omitempty"`     // other names -c accepts, e.g. "springs"
+	Tags        []string `yaml:"tags,omitempty"`        // groups the course can be searched by, e.g. "south"
+	Blacklisted bool     `yaml:"blacklisted,omitempty"` // skipped when searching all courses
+	Notes       string   `yaml:"notes,omitempty"`       // anything worth remembering about the course
+}
+
+// Defaults are used for search flags that aren't given on the command line
+type Defaults struct {
+	Time    string `yaml:"time,omitempty"`    // e.g. "07:30"
+	Spots   int    `yaml:"spots,omitempty"`   // 1-4
+	Days    int    `yaml:"days,omitempty"`    // days to search from the chosen date
+	Workers int    `yaml:"workers,omitempty"` // courses to scrape at the same time
+}
+
+// Config is the whole config file, with the courses in file order
+type Config struct {
+	Version  int      `yaml:"version"`
+	Defaults Defaults `yaml:"defaults,omitempty"`
+	Courses  []Course `yaml:"courses"`
+}
+
+// LineError is a problem with one line of the config file.
+type LineError struct {
+	Path string
+	Line int
+	Msg  string
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Msg)
+}
+
+// Errors is every problem found in a config file, in line order.
+type Errors []*LineError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Load reads the config file at path, as the old comma separated format if
+// it ends in .txt and YAML otherwise. A missing file gives an error that
+// matches os.ErrNotExist. If any lines are invalid the error is an Errors
+// listing all of them.
+func Load(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if isLegacy(path) {
+		return ParseText(file, path)
+	}
+	return Parse(file, path)
+}
+
+// Helper function to tell if a path is in the old comma separated format
+func isLegacy(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".txt")
+}
+
+// Helper function to check every course once they've been read, lines
+// holds the line each course started on for the error messages
+func (cfg *Config) check(name string, lines []int) Errors {
+	var errs Errors
+	seen := make(map[string]int) // lower case name or alias -> line
+
+	for i, c := range cfg.Courses {
+		if err := c.Validate(); err != nil {
+			errs = append(errs, &LineError{Path: name, Line: lines[i], Msg: err.Error()})
+			continue
+		}
+
+		key := strings.ToLower(c.Name)
+		if first, dup := seen[key]; dup {
+			errs = append(errs, &LineError{Path: name, Line: lines[i], Msg: fmt.Sprintf("%q is already on line %d", c.Name, first)})
+			continue
+		}
+		seen[key] = lines[i]
+
+		// an alias can't stand for two courses
+		for _, a := range c.Aliases {
+			key := strings.ToLower(strings.TrimSpace(a))
+			if first, dup := seen[key]; dup && first != lines[i] {
+				errs = append(errs, &LineError{Path: name, Line: lines[i], Msg: fmt.Sprintf("alias %q is already used on line %d", a, first)})
+				continue
+			}
+			seen[key] = lines[i]
+		}
+	}
+	return errs
+}
+
+// Validate checks a course has a name, a web URL and a website type. The
+// website type is only checked for being there, an unknown one is
+// reported when the course is searched.
+func (c Course) Validate() error {
+	switch {
+	case strings.TrimSpace(c.Name) == "":
+		return errors.New("course name is empty")
+	case c.URL == "":
+		return fmt.Errorf("%s has no URL", c.Name)
+	case c.WebsiteType == "":
+		return fmt.Errorf("%s has no website type", c.Name)
+	}
+
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s has an invalid URL %q (it should start with https://)", c.Name, c.URL)
+	}
+
+	// aliases and tags are typed as comma separated lists, so they can't
+	// have one
+	for _, a := range c.Aliases {
+		if strings.TrimSpace(a) == "" {
+			return fmt.Errorf("%s has an empty alias", c.Name)
+		}
+		if strings.Contains(a, ",") {
+			return fmt.Errorf("%s has an alias with a comma in it (%q)", c.Name, a)
+		}
+	}
+	for _, tag := range c.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("%s has an empty tag", c.Name)
+		}
+		if strings.Contains(tag, ",") {
+			return fmt.Errorf("%s has a tag with a comma in it (%q)", c.Name, tag)
+		}
+	}
+	return nil
+}
+
+// Validate checks the defaults are ones the search flags would accept.
+func (d Defaults) Validate() error {
+	if d.Time != "" && !validClock(d.Time) {
+		return fmt.Errorf("default time %q should look like 07:30", d.Time)
+	}
+	if d.Spots < 0 || d.Spots > 4 {
+		return fmt.Errorf("default spots should be between 1 and 4, not %d", d.Spots)
+	}
+	if d.Days < 0 {
+		return fmt.Errorf("default days can't be negative")
+	}
+	if d.Workers < 0 {
+		return fmt.Errorf("default workers can't be negative")
+	}
+	return nil
+}
+
+// Helper function to check a 24-hour "HH:MM" time
+func validClock(s string) bool {
+	var h, m int
+	if n, err := fmt.Sscanf(s, "%d:%d", &h, &m); n != 2 || err != nil {
+		return false
+	}
+	return len(s) == 5 && h >= 0 && h < 24 && m >= 0 && m < 60
+}
+
+// Find returns the course with the given name, ignoring case.
+func (cfg *Config) Find(name string) (Course, bool) {
+	for _, c := range cfg.Courses {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return Course{}, false
+}
+
+// Add adds a course, or replaces the one with the same name (ignoring case).
+func (cfg *Config) Add(c Course) {
+	for i := range cfg.Courses {
+		if strings.EqualFold(cfg.Courses[i].Name, c.Name) {
+			cfg.Courses[i] = c
+			return
+		}
+	}
+	cfg.Courses = append(cfg.Courses, c)
+}
+
+// Save writes the config to path in the format its name calls for,
+// creating the directory if needed. The file is written under a temp name
+// and renamed so a crash can't leave it half written.
+func Save(path string, cfg *Config) error {
+	for _, c := range cfg.Courses {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := cfg.Defaults.Validate(); err != nil {
+		return err
+	}
+
+	write := Write
+	if isLegacy(path) {
+		write = WriteText
+	}
+	return writeFile(path, func(w io.Writer) error { return write(w, cfg) })
+}
+
+// Helper function to write a file atomically
+func writeFile(path string, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".config-*")
+	if err != nil {
+		return err
+	}
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Migrate moves the courses from the old config file at legacy into a new
+// YAML config at path, and renames legacy to config.txt.bak. It does
+// nothing and returns "" unless path is missing and legacy is there,
+// otherwise it returns the backup's path.
+func Migrate(path, legacy string) (string, error) {
+	if isLegacy(path) {
+		return "", nil
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	cfg, err := Load(legacy)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := Save(path, cfg); err != nil {
+		return "", err
+	}
+	backup := legacy + ".bak"
+	if err := os.Rename(legacy, backup); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
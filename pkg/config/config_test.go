@@ -0,0 +1,281 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseText(t *testing.T) {
+	content := "# courses\n" +
+		"\n" +
+		"  Collier Park Golf Course , https://bookings.collierparkgolf.com.au , miclub , True\n" +
+		"Hamersley Golf Course,https://hamersley.quick18.com/teetimes/searchmatrix,quick18\n" +
+		"Fremantle Golf Course,https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000,miclub,\n"
+
+	cfg, err := ParseText(strings.NewReader(content), "config.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []Course{
+		{Name: "Collier Park Golf Course", URL: "https://bookings.collierparkgolf.com.au", WebsiteType: "miclub", Blacklisted: true},
+		{Name: "Hamersley Golf Course", URL: "https://hamersley.quick18.com/teetimes/searchmatrix", WebsiteType: "quick18"},
+		{Name: "Fremantle Golf Course", URL: "https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000", WebsiteType: "miclub"},
+	}, cfg.Courses)
+}
+
+func TestParseTextErrors(t *testing.T) {
+	content := "Collier Park Golf Course,https://bookings.collierparkgolf.com.au,miclub\n" +
+		"# fine\n" +
+		"Hamersley Golf Course,https://hamersley.quick18.com\n" +
+		"Springs,springs.quick18.com,quick18\n" +
+		"Fremantle,https://fremantlepublic.miclub.com.au,miclub,yes\n" +
+		"collier park golf course,https://bookings.collierparkgolf.com.au,miclub\n" +
+		",https://example.com,miclub\n"
+
+	_, err := ParseText(strings.NewReader(content), "config.txt")
+	var errs Errors
+	require.True(t, errors.As(err, &errs), "expected every problem, not just the first")
+
+	lines := make([]int, len(errs))
+	for i, e := range errs {
+		lines[i] = e.Line
+		assert.Equal(t, "config.txt", e.Path)
+	}
+	assert.Equal(t, []int{3, 4, 5, 6, 7}, lines)
+
+	assert.Contains(t, errs[0].Error(), "config.txt:3: expected name,URL,website type")
+	assert.Contains(t, errs[1].Error(), "invalid URL")
+	assert.Contains(t, errs[2].Error(), `not "yes"`)
+	assert.Contains(t, errs[3].Error(), "already on line 1")
+	assert.Contains(t, errs[4].Error(), "name is empty")
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "config.txt"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestSaveAndLoadText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".config", "TeeTimeFinder", "config.txt")
+
+	cfg := &Config{}
+	cfg.Add(Course{Name: "Fremantle Golf Course", URL: "https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000", WebsiteType: "miclub"})
+	cfg.Add(Course{Name: "The Springs Golf Course", URL: "https://springs.quick18.com/teetimes/searchmatrix", WebsiteType: "Quick18"})
+	require.NoError(t, Save(path, cfg), "Save should create the directory")
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	expected := "" +
+		"Fremantle Golf Course,https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000,miclub,false\n" +
+		"The Springs Golf Course,https://springs.quick18.com/teetimes/searchmatrix,Quick18,false\n"
+	assert.Equal(t, expected, string(got))
+
+	// Adding a course that's already there replaces it
+	cfg.Add(Course{Name: "the springs golf course", URL: "https://springs.quick18.com/teetimes/searchmatrix", WebsiteType: "quick18", Blacklisted: true})
+	require.NoError(t, Save(path, cfg))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, loaded.Courses, 2)
+	springs, ok := loaded.Find("The Springs Golf Course")
+	require.True(t, ok)
+	assert.True(t, springs.Blacklisted)
+	assert.Equal(t, "quick18", springs.WebsiteType)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temp files left behind")
+}
+
+func TestSaveRejectsBadCourse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.txt")
+	require.NoError(t, os.WriteFile(path, []byte("Keep,https://keep.example.com,miclub,false\n"), 0o644))
+
+	cfg := &Config{Courses: []Course{{Name: "Collier Park, Como", URL: "https://bookings.collierparkgolf.com.au", WebsiteType: "miclub"}}}
+	err := Save(path, cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "comma")
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Keep,https://keep.example.com,miclub,false\n", string(got), "the old file is left alone")
+}
+
+func TestParse(t *testing.T) {
+	content := `version: 1
+defaults:
+  time: "07:30"
+  spots: 2
+courses:
+  - name: Collier Park, Como
+    url: https://bookings.collierparkgolf.com.au
+    type: miclub
+    blacklisted: true
+  - name: Hamersley Golf Course
+    url: https://hamersley.quick18.com/teetimes/searchmatrix
+    type: quick18
+    aliases: [hamo, Hamersley]
+    tags: [north, metro]
+    notes: Back 9 is shut on Mondays
+`
+	cfg, err := Parse(strings.NewReader(content), "config.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, Defaults{Time: "07:30", Spots: 2}, cfg.Defaults)
+	assert.Equal(t, []Course{
+		{Name: "Collier Park, Como", URL: "https://bookings.collierparkgolf.com.au", WebsiteType: "miclub", Blacklisted: true},
+		{Name: "Hamersley Golf Course", URL: "https://hamersley.quick18.com/teetimes/searchmatrix", WebsiteType: "quick18", Aliases: []string{"hamo", "Hamersley"}, Tags: []string{"north", "metro"}, Notes: "Back 9 is shut on Mondays"},
+	}, cfg.Courses)
+
+	cfg, err = Parse(strings.NewReader(""), "config.yaml")
+	require.NoError(t, err, "an empty file is an empty config")
+	assert.Equal(t, Version, cfg.Version)
+	assert.Empty(t, cfg.Courses)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name: "bad courses",
+			content: `courses:
+  - name: Collier Park
+    url: https://bookings.collierparkgolf.com.au
+    type: miclub
+  - name: Hamersley
+    url: hamersley.quick18.com
+    type: quick18
+  - name: collier park
+    url: https://bookings.collierparkgolf.com.au
+    type: miclub
+`,
+			want: []string{"config.yaml:5: Hamersley has an invalid URL", `config.yaml:8: "collier park" is already on line 2`},
+		},
+		{
+			name:    "clashing aliases",
+			content: "courses:\n  - name: Collier Park\n    aliases: [cp, collier]\n    url: https://bookings.collierparkgolf.com.au\n    type: miclub\n  - name: Collier\n    url: https://bookings.collierparkgolf.com.au\n    type: miclub\n  - name: Crystal Palms\n    aliases: [CP, \"\"]\n    url: https://crystalpalms.miclub.com.au\n    type: miclub\n  - name: Cottesloe\n    aliases: [cp]\n    url: https://cottesloe.miclub.com.au\n    type: miclub\n",
+			want:    []string{`config.yaml:6: "Collier" is already on line 2`, "config.yaml:9: Crystal Palms has an empty alias", `config.yaml:13: alias "cp" is already used on line 2`},
+		},
+		{
+			name:    "bad tags",
+			content: "courses:\n  - name: Collier Park\n    url: https://bookings.collierparkgolf.com.au\n    type: miclub\n    tags: [\"south,coast\"]\n  - name: Hamersley\n    url: https://hamersley.quick18.com\n    type: quick18\n    tags: [\" \"]\n",
+			want:    []string{`config.yaml:2: Collier Park has a tag with a comma in it ("south,coast")`, "config.yaml:6: Hamersley has an empty tag"},
+		},
+		{
+			name:    "typo in a key",
+			content: "courses:\n  - name: Collier Park\n    ulr: https://bookings.collierparkgolf.com.au\n",
+			want:    []string{"config.yaml:3: field ulr not found"},
+		},
+		{
+			name:    "wrong type",
+			content: "courses:\n  - name: Collier Park\n    url: https://bookings.collierparkgolf.com.au\n    type: miclub\n    blacklisted: maybe\n",
+			want:    []string{"config.yaml:5: cannot unmarshal"},
+		},
+		{
+			name:    "not YAML",
+			content: "courses:\n  - name: [Collier\n",
+			want:    []string{"config.yaml:"},
+		},
+		{
+			name:    "bad defaults",
+			content: "version: 1\ndefaults:\n  spots: 9\n",
+			want:    []string{"config.yaml:3: default spots should be between 1 and 4"},
+		},
+		{
+			name:    "newer version",
+			content: "version: 99\ncourses: []\n",
+			want:    []string{"config.yaml:1: config version 99 is newer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.content), "config.yaml")
+			var errs Errors
+			require.True(t, errors.As(err, &errs), "expected line errors, got %v", err)
+			require.Len(t, errs, len(tt.want))
+			for i, want := range tt.want {
+				assert.Contains(t, errs[i].Error(), want)
+			}
+		})
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	cfg := &Config{Defaults: Defaults{Spots: 2}}
+	cfg.Add(Course{Name: "Collier Park, Como", URL: "https://bookings.collierparkgolf.com.au", WebsiteType: "miclub", Notes: "Ring the pro shop for twilight"})
+	cfg.Add(Course{Name: "The Springs Golf Course", URL: "https://springs.quick18.com/teetimes/searchmatrix", WebsiteType: "quick18", Blacklisted: true})
+	require.NoError(t, Save(path, cfg))
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(got), "version: 1\n"), "the schema version comes first")
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, Version, loaded.Version)
+	assert.Equal(t, cfg.Defaults, loaded.Defaults)
+	assert.Equal(t, cfg.Courses, loaded.Courses)
+}
+
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	legacy := filepath.Join(dir, LegacyFile)
+	content := "Collier Park Golf Course,https://bookings.collierparkgolf.com.au,miclub,true\n" +
+		"Hamersley Golf Course,https://hamersley.quick18.com/teetimes/searchmatrix,quick18\n"
+
+	backup, err := Migrate(path, legacy)
+	require.NoError(t, err)
+	assert.Empty(t, backup, "nothing to migrate")
+
+	require.NoError(t, os.WriteFile(legacy, []byte(content), 0o644))
+	backup, err = Migrate(path, legacy)
+	require.NoError(t, err)
+	assert.Equal(t, legacy+".bak", backup)
+
+	old, err := os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(old), "the backup is the old file untouched")
+	assert.NoFileExists(t, legacy)
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Course{
+		{Name: "Collier Park Golf Course", URL: "https://bookings.collierparkgolf.com.au", WebsiteType: "miclub", Blacklisted: true},
+		{Name: "Hamersley Golf Course", URL: "https://hamersley.quick18.com/teetimes/searchmatrix", WebsiteType: "quick18"},
+	}, cfg.Courses)
+
+	// Once there's a YAML config a stray config.txt is left alone
+	require.NoError(t, os.WriteFile(legacy, []byte(content), 0o644))
+	backup, err = Migrate(path, legacy)
+	require.NoError(t, err)
+	assert.Empty(t, backup)
+	assert.FileExists(t, legacy)
+}
+
+func TestMigrateBrokenLegacy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	legacy := filepath.Join(dir, LegacyFile)
+	require.NoError(t, os.WriteFile(legacy, []byte("Hamersley Golf Course\n"), 0o644))
+
+	_, err := Migrate(path, legacy)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), legacy+":1:")
+	assert.NoFileExists(t, path)
+	assert.FileExists(t, legacy, "a config that can't be read isn't moved")
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseText reads the old comma separated config file from r, one
+// name,URL,website type[,blacklisted] per line. Blank lines and lines
+// starting with # are ignored. name is used in error messages.
+func ParseText(r io.Reader, name string) (*Config, error) {
+	cfg := &Config{Version: Version}
+	var errs Errors
+	var lines []int
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue // Skip empty lines and comments
+		}
+
+		c, err := parseLine(line)
+		if err != nil {
+			errs = append(errs, &LineError{Path: name, Line: lineNo, Msg: err.Error()})
+			continue
+		}
+		cfg.Courses = append(cfg.Courses, c)
+		lines = append(lines, lineNo)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	errs = append(errs, cfg.check(name, lines)...)
+	if len(errs) > 0 {
+		sortErrors(errs)
+		return nil, errs
+	}
+	return cfg, nil
+}
+
+// Helper function to split one line into a course
+func parseLine(line string) (Course, error) {
+	parts := strings.SplitN(line, ",", 4)
+	if len(parts) < 3 {
+		return Course{}, fmt.Errorf("expected name,URL,website type[,blacklisted] but found %d field(s)", len(parts))
+	}
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	c := Course{Name: parts[0], URL: parts[1], WebsiteType: parts[2]}
+	if len(parts) == 4 {
+		switch strings.ToLower(parts[3]) {
+		case "true":
+			c.Blacklisted = true
+		case "false", "":
+		default:
+			return Course{}, fmt.Errorf("blacklisted should be true or false, not %q", parts[3])
+		}
+	}
+	return c, nil
+}
+
+// WriteText writes the courses in the old comma separated format to w.
+// Anything the old format has no room for, like notes, aliases and tags, is left out.
+func WriteText(w io.Writer, cfg *Config) error {
+	for _, c := range cfg.Courses {
+		if strings.Contains(c.Name+c.URL+c.WebsiteType, ",") {
+			return fmt.Errorf("%s can't be saved to a %s file because it has a comma in it", c.Name, LegacyFile)
+		}
+		if _, err := fmt.Fprintf(w, "%s,%s,%s,%t\n", c.Name, c.URL, c.WebsiteType, c.Blacklisted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parse reads a YAML config file from r. name is used in error messages.
+// Unknown keys are an error so a typo doesn't go unnoticed.
+func Parse(r io.Reader, name string) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	cfg := &Config{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, yamlErrors(name, err)
+	}
+
+	// The decode worked so the file is valid YAML, read it again as nodes
+	// to find which line each course is on
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlErrors(name, err)
+	}
+
+	if cfg.Version == 0 {
+		cfg.Version = Version
+	}
+	if cfg.Version > Version {
+		return nil, Errors{{Path: name, Line: lineOf(mappingValue(&doc, "version")), Msg: fmt.Sprintf("config version %d is newer than this TeeTimeFinder understands (%d), please update TeeTimeFinder", cfg.Version, Version)}}
+	}
+
+	var errs Errors
+	if err := cfg.Defaults.Validate(); err != nil {
+		errs = append(errs, &LineError{Path: name, Line: lineOf(mappingValue(&doc, "defaults")), Msg: err.Error()})
+	}
+
+	lines := make([]int, len(cfg.Courses))
+	if seq := mappingValue(&doc, "courses"); seq != nil {
+		for i := range lines {
+			if i < len(seq.Content) {
+				lines[i] = seq.Content[i].Line
+			}
+		}
+	}
+	errs = append(errs, cfg.check(name, lines)...)
+
+	if len(errs) > 0 {
+		sortErrors(errs)
+		return nil, errs
+	}
+	return cfg, nil
+}
+
+// Write writes the config as YAML to w, with the current schema version.
+func Write(w io.Writer, cfg *Config) error {
+	out := *cfg
+	out.Version = Version
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&out); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// Helper function to find the value of a top level key in a YAML document
+func mappingValue(doc *yaml.Node, key string) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	m := doc.Content[0]
+	if m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Helper function to get a node's line, or 1 if there's no node
+func lineOf(n *yaml.Node) int {
+	if n == nil {
+		return 1
+	}
+	return n.Line
+}
+
+var yamlLineRe = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// Helper function to turn the YAML library's errors, which have the line
+// number inside the message, into LineErrors
+func yamlErrors(name string, err error) error {
+	msgs := []string{err.Error()}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		msgs = typeErr.Errors
+	}
+
+	var errs Errors
+	for _, msg := range msgs {
+		m := yamlLineRe.FindStringSubmatch(msg)
+		if m == nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		line, _ := strconv.Atoi(m[1])
+		errs = append(errs, &LineError{Path: name, Line: line, Msg: m[2]})
+	}
+	return errs
+}
+
+// Helper function to put errors in line order
+func sortErrors(errs Errors) {
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Line < errs[j].Line })
+}
@@ -0,0 +1,309 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+// Package fakesite is a stand-in for the MiClub and Quick18 booking sites.
+//
+// A Site serves made up calendar, timesheet and searchmatrix pages for the
+// courses added to it, in the same shape as the real sites, so the whole
+// search can be run without the internet:
+//
+//	site := fakesite.New(fakesite.Course{
+//		Platform: fakesite.MiClub,
+//		ID:       "north",
+//		Times: map[string]map[string][]fakesite.TeeTime{
+//			"2025-10-18": {"18 Holes": {{Time: "07:30", Layout: "Main", Spots: 4, Fee: 49}}},
+//		},
+//	})
+//	srv := httptest.NewServer(site)
+//	courseURL := site.CourseURL(srv.URL, "north")
+//
+// Several courses can share one Site. MiClub courses are told apart by their
+// booking_resource_id and Quick18 courses by a path prefix, since those are
+// the parts of the course URL the scrapers keep.
+package fakesite
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Platform is the booking website a course pretends to be
+type Platform string
+
+const (
+	MiClub  Platform = "miclub"
+	Quick18 Platform = "quick18"
+)
+
+// TeeTime is one tee time for a game on a given day
+type TeeTime struct {
+	Time   string  // 24-hour "HH:MM"
+	Layout string  // e.g. "Front 9", MiClub shows it under the time
+	Spots  int     // free spots out of 4, 0 is fully booked
+	Fee    float64 // green fee shown beside the time, 0 for none
+}
+
+// Course is one made up course and its availability
+type Course struct {
+	Platform Platform
+	ID       string // booking_resource_id for MiClub, path prefix for Quick18
+
+	// Times lists each day's tee times by date (YYYY-MM-DD) and then game.
+	// Every game mentioned on any day is listed on every page, like the
+	// real sites do, so a game with no times that day shows as booked out.
+	Times map[string]map[string][]TeeTime
+
+	Status int           // if set, every page answers with this status instead
+	Delay  time.Duration // wait this long before answering, to test timeouts
+}
+
+// calendarDays is how many days a MiClub calendar page shows
+const calendarDays = 6
+
+// Site serves the pages for a set of courses. It is safe to change
+// availability while a search is running.
+type Site struct {
+	mu       sync.Mutex
+	courses  map[string]*Course
+	requests int
+}
+
+// New returns a Site serving the given courses.
+func New(courses ...Course) *Site {
+	s := &Site{courses: make(map[string]*Course)}
+	for _, c := range courses {
+		s.Add(c)
+	}
+	return s
+}
+
+// Add adds a course, replacing any with the same ID.
+func (s *Site) Add(c Course) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c.Times == nil {
+		c.Times = make(map[string]map[string][]TeeTime)
+	}
+	s.courses[c.ID] = &c
+}
+
+// SetTimes replaces a game's tee times on one day (YYYY-MM-DD).
+func (s *Site) SetTimes(id, date, game string, times []TeeTime) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.courses[id]
+	if !ok {
+		return
+	}
+	if c.Times[date] == nil {
+		c.Times[date] = make(map[string][]TeeTime)
+	}
+	c.Times[date][game] = times
+}
+
+// Requests reports how many pages have been served so far.
+func (s *Site) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// CourseURL returns the URL to put in the config for a course, given the
+// base URL the Site is served at (e.g. an httptest.Server's URL).
+func (s *Site) CourseURL(baseURL, id string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	base := strings.TrimRight(baseURL, "/")
+	c, ok := s.courses[id]
+	if !ok {
+		return ""
+	}
+	if c.Platform == Quick18 {
+		return base + "/" + url.PathEscape(id) + "/teetimes/searchmatrix"
+	}
+	return base + "/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=" + url.QueryEscape(id)
+}
+
+// ServeHTTP routes a request to the course and page it is for.
+func (s *Site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c, page, ok := s.route(r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if c.Delay > 0 {
+		select {
+		case <-time.After(c.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if c.Status != 0 {
+		http.Error(w, http.StatusText(c.Status), c.Status)
+		return
+	}
+
+	// render the whole page first so a bad request gets a clean error
+	var buf bytes.Buffer
+	var err error
+	q := r.URL.Query()
+	switch page {
+	case "calendar":
+		err = s.serveCalendar(&buf, c, q.Get("selectedDate"))
+	case "timesheet":
+		err = s.serveTimesheet(&buf, c, q.Get("selectedDate"), q.Get("feeGroupId"))
+	case "matrix":
+		err = s.serveMatrix(&buf, c, r.URL.Path, q.Get("teedate"))
+	case "booking":
+		err = bookingTemplate.Execute(&buf, r.URL.Path)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// Helper function to work out which course and page a request is for, and
+// count it. The course is copied so the page is rendered from a consistent
+// snapshot even if SetTimes is called meanwhile.
+func (s *Site) route(r *http.Request) (Course, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+
+	find := func(id string) (Course, bool) {
+		c, ok := s.courses[id]
+		if !ok {
+			return Course{}, false
+		}
+		snap := *c
+		snap.Times = make(map[string]map[string][]TeeTime, len(c.Times))
+		for date, games := range c.Times {
+			snap.Times[date] = make(map[string][]TeeTime, len(games))
+			for game, times := range games {
+				snap.Times[date][game] = append([]TeeTime(nil), times...)
+			}
+		}
+		return snap, true
+	}
+
+	switch r.URL.Path {
+	case "/guests/bookings/ViewPublicCalendar.msp":
+		c, ok := find(r.URL.Query().Get("booking_resource_id"))
+		return c, "calendar", ok && c.Platform == MiClub
+	case "/guests/bookings/ViewPublicTimesheet.msp":
+		c, ok := find(r.URL.Query().Get("booking_resource_id"))
+		return c, "timesheet", ok && c.Platform == MiClub
+	}
+
+	// Quick18 pages all start with the course's prefix
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 3)
+	if len(parts) < 3 || parts[1] != "teetimes" {
+		return Course{}, "", false
+	}
+	id, err := url.PathUnescape(parts[0])
+	if err != nil {
+		return Course{}, "", false
+	}
+	c, ok := find(id)
+	if !ok || c.Platform != Quick18 {
+		return Course{}, "", false
+	}
+	switch {
+	case parts[2] == "searchmatrix":
+		return c, "matrix", true
+	case strings.HasPrefix(parts[2], "teetime/"):
+		return c, "booking", true
+	}
+	return Course{}, "", false
+}
+
+// Helper function to list every game the course has, in a stable order
+func (c Course) games() []string {
+	seen := make(map[string]bool)
+	var games []string
+	for _, day := range c.Times {
+		for game := range day {
+			if !seen[game] {
+				seen[game] = true
+				games = append(games, game)
+			}
+		}
+	}
+	sort.Strings(games)
+	return games
+}
+
+// Helper function to make up a MiClub fee group id for a game
+func feeGroupID(game string) string {
+	h := fnv.New32a()
+	h.Write([]byte(game))
+	return fmt.Sprint(1500000000 + h.Sum32()%100000000)
+}
+
+// Helper function to get a game's times on a day sorted by time, and
+// whether any of them can be booked
+func (c Course) timesFor(date, game string) ([]TeeTime, bool) {
+	times := append([]TeeTime(nil), c.Times[date][game]...)
+	sort.SliceStable(times, func(i, j int) bool { return times[i].Time < times[j].Time })
+
+	open := false
+	for _, t := range times {
+		if t.Spots > 0 {
+			open = true
+		}
+	}
+	return times, open
+}
+
+// Helper function to read a YYYY-MM-DD date, or a Quick18 YYYYMMDD one.
+// Like the real sites, a page asked for without a date shows today.
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		y, m, d := time.Now().Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), nil
+	}
+	if d, err := time.Parse("2006-01-02", s); err == nil {
+		return d, nil
+	}
+	if d, err := time.Parse("20060102", s); err == nil {
+		return d, nil
+	}
+	return time.Time{}, fmt.Errorf("bad date %q", s)
+}
+
+// Helper function to turn "14:30" into the 12-hour pieces the sites show
+func clock(hhmm string) (string, string) {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return hhmm, ""
+	}
+	if t.Hour() < 12 {
+		return t.Format("3:04"), "AM"
+	}
+	return t.Format("3:04"), "PM"
+}
+
+// Helper function to turn "14:30" into MiClub's "02:30 pm"
+func miclubTime(hhmm string) string {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return hhmm
+	}
+	return t.Format("03:04 pm")
+}
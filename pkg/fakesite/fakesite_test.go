@@ -0,0 +1,177 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package fakesite_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fakesite"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/miclub"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/provider"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/quick18"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	day      = time.Date(2025, 10, 18, 0, 0, 0, 0, time.Local)
+	dayISO   = day.Format("2006-01-02")
+	nextWeek = day.AddDate(0, 0, 7).Format("2006-01-02")
+)
+
+// Helper function to serve a site with one course on each platform
+func newTestSite(t *testing.T) (*fakesite.Site, *httptest.Server) {
+	site := fakesite.New(
+		fakesite.Course{
+			Platform: fakesite.MiClub,
+			ID:       "3000000",
+			Times: map[string]map[string][]fakesite.TeeTime{
+				dayISO: {
+					"18 Holes": {
+						{Time: "14:30", Layout: "Main", Spots: 2, Fee: 49},
+						{Time: "07:00", Layout: "Main", Spots: 4, Fee: 49},
+						{Time: "07:08", Layout: "Main", Spots: 0, Fee: 49},
+					},
+					"9 Holes": {{Time: "06:00", Layout: "Front 9", Spots: 0}},
+				},
+				nextWeek: {"9 Holes": {{Time: "16:00", Layout: "Back 9", Spots: 3, Fee: 28.5}}},
+			},
+		},
+		fakesite.Course{
+			Platform: fakesite.Quick18,
+			ID:       "hamersley",
+			Times: map[string]map[string][]fakesite.TeeTime{
+				dayISO: {
+					"9 Holes":  {{Time: "06:07", Layout: "Back 9 Morning", Spots: 1, Fee: 33}},
+					"18 holes": {{Time: "06:07", Layout: "Back 9 Morning", Spots: 1, Fee: 55}, {Time: "13:00", Layout: "Full", Spots: 0}},
+				},
+			},
+		},
+	)
+	srv := httptest.NewServer(site)
+	t.Cleanup(srv.Close)
+	return site, srv
+}
+
+// Helper function to get a Fetcher without the polite delay
+func testFetcher() *fetch.Fetcher {
+	f := fetch.New()
+	f.Delay = 0
+	return f
+}
+
+func TestMiClubPages(t *testing.T) {
+	site, srv := newTestSite(t)
+	ctx := context.Background()
+	courseURL := site.CourseURL(srv.URL, "3000000")
+
+	games, err := miclub.ScrapeDates(ctx, testFetcher(), courseURL, day)
+	require.NoError(t, err)
+	assert.Len(t, games, 1, "a game that's booked out isn't listed")
+	require.Contains(t, games, "18 Holes")
+
+	times, err := miclub.ScrapeTimes(ctx, testFetcher(), games["18 Holes"])
+	require.NoError(t, err)
+	require.Len(t, times["Main"], 2)
+	assert.Equal(t, "07:00 am", times["Main"][0].Time)
+	assert.Equal(t, 4, times["Main"][0].AvailableSpots)
+	assert.Equal(t, "02:30 pm", times["Main"][1].Time)
+	assert.Equal(t, 2, times["Main"][1].AvailableSpots)
+	require.Len(t, times["Main"][0].Fees, 1)
+	assert.Equal(t, 49.0, times["Main"][0].Fees[0].Amount)
+
+	// The calendar shows six days, so next week is a second page
+	byDate, err := miclub.ScrapeDateRange(ctx, testFetcher(), courseURL, day, day.AddDate(0, 0, 7))
+	require.NoError(t, err)
+	assert.Len(t, byDate, 2, "only days with something to book are returned")
+	assert.Contains(t, byDate[dayISO], "18 Holes")
+	assert.Contains(t, byDate[nextWeek], "9 Holes")
+}
+
+func TestQuick18Pages(t *testing.T) {
+	site, srv := newTestSite(t)
+	ctx := context.Background()
+	courseURL := site.CourseURL(srv.URL, "hamersley")
+
+	games, err := quick18.ScrapeDates(ctx, testFetcher(), courseURL, day)
+	require.NoError(t, err)
+	assert.Len(t, games, 2)
+
+	times, err := quick18.ScrapeTimes(ctx, testFetcher(), games["18 holes"])
+	require.NoError(t, err)
+	slots := times["18 Holes"]["Back 9 Morning"]
+	require.Len(t, slots, 1)
+	assert.Equal(t, "6:07AM", slots[0].Time)
+	assert.Equal(t, 1, slots[0].AvailableSpots)
+	assert.True(t, strings.HasPrefix(slots[0].BookingURL, srv.URL+"/hamersley/teetimes/teetime/202510180607"))
+	assert.Empty(t, times["18 Holes"]["Full"], "a booked out time isn't listed")
+
+	resp, err := http.Get(slots[0].BookingURL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "booking links lead somewhere")
+
+	// A day without any times shows a message instead of the table
+	games, err = quick18.ScrapeDates(ctx, testFetcher(), courseURL, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Empty(t, games)
+}
+
+func TestCourseURLsAreDetected(t *testing.T) {
+	site, srv := newTestSite(t)
+
+	// Quick18 courses sit under their ID, so the searchmatrix page isn't at
+	// the start of the path like it is on quick18.com
+	for id, want := range map[string]string{"3000000": "miclub", "hamersley": "quick18"} {
+		p, ok := provider.Detect(site.CourseURL(srv.URL, id))
+		require.True(t, ok, id)
+		assert.Equal(t, want, p.Name(), id)
+	}
+}
+
+func TestSetTimes(t *testing.T) {
+	site, srv := newTestSite(t)
+	ctx := context.Background()
+	courseURL := site.CourseURL(srv.URL, "3000000")
+
+	site.SetTimes("3000000", dayISO, "9 Holes", []fakesite.TeeTime{{Time: "06:00", Layout: "Front 9", Spots: 1}})
+	games, err := miclub.ScrapeDates(ctx, testFetcher(), courseURL, day)
+	require.NoError(t, err)
+	assert.Contains(t, games, "9 Holes", "a cancellation shows up on the next search")
+	assert.Equal(t, 1, site.Requests())
+}
+
+func TestBrokenCourses(t *testing.T) {
+	site := fakesite.New(
+		fakesite.Course{Platform: fakesite.MiClub, ID: "1", Status: http.StatusServiceUnavailable},
+		fakesite.Course{Platform: fakesite.Quick18, ID: "slow", Delay: time.Minute},
+	)
+	srv := httptest.NewServer(site)
+	defer srv.Close()
+
+	var status *fetch.StatusError
+	_, err := miclub.ScrapeDates(context.Background(), testFetcher(), site.CourseURL(srv.URL, "1"), day)
+	require.ErrorAs(t, err, &status)
+	assert.Equal(t, http.StatusServiceUnavailable, status.StatusCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = quick18.ScrapeDates(ctx, testFetcher(), site.CourseURL(srv.URL, "slow"), day)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Empty(t, site.CourseURL(srv.URL, "nowhere"))
+	resp, err := http.Get(srv.URL + "/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=nowhere")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,309 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package fakesite
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+// The templates only keep the parts of the real pages the scrapers read,
+// with the same classes and nesting.
+
+var calendarTemplate = template.Must(template.New("calendar").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Public Calendar</title></head>
+<body>
+<div class="row-wrapper">
+{{- range $row := .}}
+  <div class="row feeGroupRow feeGroupId-{{.FeeGroupID}}" data-feeid="{{.FeeGroupID}}">
+    <div class="row-heading"><h3>{{.Game}}</h3></div>
+    <div class="items-wrapper">
+    {{- range .Cells}}
+    {{- if .Open}}
+      <div class="cell" data-date="{{.Offset}}" onclick="javascript:redirectToTimesheet('{{$row.FeeGroupID}}','{{.Date}}');" data-feeid="{{$row.FeeGroupID}}">
+        {{- if .Price}}<p class="price">{{.Price}}</p>{{else}}<p>Available</p>{{end -}}
+      </div>
+    {{- else}}
+      <div class="cell cell-na" data-date="{{.Offset}}"><p>No Bookings Available</p></div>
+    {{- end}}
+    {{- end}}
+    </div>
+  </div>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+var timesheetTemplate = template.Must(template.New("timesheet").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Public Timesheet</title></head>
+<body>
+{{- range $row := .}}
+<div id="row-{{.ID}}" class="row row-time" data-value="{{.ID}}">
+  <div class="row-heading">
+    <div class="row">
+      <div class="row-heading-inner time-wrapper">
+        <h3>{{.Time}}</h3>
+        <h4>{{.Layout}}</h4>
+      </div>
+      <div class="row-heading-inner fees-wrapper">
+        {{- if .Fee}}
+        <ul><li><span class="price">{{.Fee}}</span> {{.FeeLabel}}</li></ul>
+        {{- end}}
+      </div>
+    </div>
+  </div>
+  <div class="records-wrapper">
+  {{- range $i, $free := $row.Cells}}
+    {{- if $free}}
+    <div id="{{$row.ID}}_{{$i}}" class="cell cell-available" data-value="{{$row.ID}}"><p class="small">Available</p></div>
+    {{- else}}
+    <div id="{{$row.ID}}_{{$i}}" class="cell cell-taken" data-value="{{$row.ID}}"><p class="small">Taken</p></div>
+    {{- end}}
+  {{- end}}
+  </div>
+</div>
+{{- end}}
+</body>
+</html>
+`))
+
+var matrixTemplate = template.Must(template.New("matrix").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Tee Times</title></head>
+<body>
+{{- if .Rows}}
+<table class="matrixTable" border="1">
+  <thead>
+    <tr>
+      <th class="mtrxHdrTeeTimes">Tee Time</th>
+      <th class="mtrxHdrCourse">Course</th>
+      <th class="matrixHdrPlayers">Players</th>
+      {{- range .Games}}
+      <th class="matrixHdrSched">{{.}}</th>
+      {{- end}}
+    </tr>
+  </thead>
+  <tbody>
+  {{- range .Rows}}
+    <tr>
+      <td class="mtrxTeeTimes">{{.Time}}<div class="be_tee_time_ampm">{{.AMPM}}</div></td>
+      <td class="mtrxCourse">{{.Layout}}</td>
+      <td class="matrixPlayers">{{.Players}}</td>
+      {{- range .Cells}}
+      {{- if .Open}}
+      <td class="matrixsched ">
+        {{- if .Price}}<div class="mtrxPrice">{{.Price}}</div>{{end}}
+        <div class="mtrxSelect"><a class="sexybutton teebutton" href="{{.Href}}"><span><span><span class="ok">Select</span></span></span></a></div>
+      </td>
+      {{- else}}
+      <td class="matrixsched mtrxInactive"><div class="mtrxPriceNA">N/A</div></td>
+      {{- end}}
+      {{- end}}
+    </tr>
+  {{- end}}
+  </tbody>
+</table>
+{{- else}}
+<div class="noTeeTimes">There are no tee times available for the date selected.</div>
+{{- end}}
+</body>
+</html>
+`))
+
+var bookingTemplate = template.Must(template.New("booking").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Book Tee Time</title></head>
+<body><p>Booking {{.}}</p></body>
+</html>
+`))
+
+type calendarRow struct {
+	Game       string
+	FeeGroupID string
+	Cells      []calendarCell
+}
+
+type calendarCell struct {
+	Offset int
+	Date   string
+	Open   bool
+	Price  string
+}
+
+type timesheetRow struct {
+	ID       string
+	Time     string // e.g. "07:30 am"
+	Layout   string
+	Fee      string
+	FeeLabel string
+	Cells    []bool // one per player spot, true if free
+}
+
+type matrixPage struct {
+	Games []string
+	Rows  []*matrixRow
+}
+
+type matrixRow struct {
+	Time    string // e.g. "7:30"
+	AMPM    string
+	Layout  string
+	Players string
+	Cells   []matrixCell // one per game
+}
+
+type matrixCell struct {
+	Open  bool
+	Price string
+	Href  string
+}
+
+// Helper function to render the MiClub calendar, six days from selectedDate
+func (s *Site) serveCalendar(w io.Writer, c Course, selectedDate string) error {
+	start, err := parseDate(selectedDate)
+	if err != nil {
+		return err
+	}
+
+	var rows []calendarRow
+	for _, game := range c.games() {
+		row := calendarRow{Game: game, FeeGroupID: feeGroupID(game)}
+		for offset := 0; offset < calendarDays; offset++ {
+			date := start.AddDate(0, 0, offset).Format("2006-01-02")
+			times, open := c.timesFor(date, game)
+			row.Cells = append(row.Cells, calendarCell{
+				Offset: offset,
+				Date:   date,
+				Open:   open,
+				Price:  cheapest(times),
+			})
+		}
+		rows = append(rows, row)
+	}
+	return calendarTemplate.Execute(w, rows)
+}
+
+// Helper function to render one game's MiClub timesheet for a day
+func (s *Site) serveTimesheet(w io.Writer, c Course, selectedDate, feeGroup string) error {
+	day, err := parseDate(selectedDate)
+	if err != nil {
+		return err
+	}
+	date := day.Format("2006-01-02")
+
+	for _, game := range c.games() {
+		if feeGroupID(game) != feeGroup {
+			continue
+		}
+
+		times, _ := c.timesFor(date, game)
+		var rows []timesheetRow
+		for i, t := range times {
+			row := timesheetRow{
+				ID:     fmt.Sprintf("%s%02d", feeGroup, i),
+				Time:   miclubTime(t.Time),
+				Layout: t.Layout,
+				Cells:  make([]bool, 4),
+			}
+			if t.Fee > 0 {
+				row.Fee = money(t.Fee)
+				row.FeeLabel = game
+			}
+			for j := 0; j < t.Spots && j < len(row.Cells); j++ {
+				row.Cells[len(row.Cells)-1-j] = true
+			}
+			rows = append(rows, row)
+		}
+		return timesheetTemplate.Execute(w, rows)
+	}
+	return fmt.Errorf("no fee group %s", feeGroup)
+}
+
+// Helper function to render a Quick18 searchmatrix page for one day
+func (s *Site) serveMatrix(w io.Writer, c Course, path, teedate string) error {
+	day, err := parseDate(teedate)
+	if err != nil {
+		return err
+	}
+	date := day.Format("2006-01-02")
+	base := strings.TrimSuffix(path, "/searchmatrix")
+
+	page := matrixPage{}
+	if len(c.Times[date]) == 0 {
+		return matrixTemplate.Execute(w, page)
+	}
+	page.Games = c.games()
+
+	// One row per time, layout and number of players, with a cell per game
+	rows := make(map[string]*matrixRow)
+	var order []string
+	for i, game := range page.Games {
+		times, _ := c.timesFor(date, game)
+		for _, t := range times {
+			key := fmt.Sprintf("%s|%s|%d", t.Time, t.Layout, t.Spots)
+			row, ok := rows[key]
+			if !ok {
+				hm, ampm := clock(t.Time)
+				row = &matrixRow{
+					Time:    hm,
+					AMPM:    ampm,
+					Layout:  t.Layout,
+					Players: players(t.Spots),
+					Cells:   make([]matrixCell, len(page.Games)),
+				}
+				rows[key] = row
+				order = append(order, key)
+			}
+			if t.Spots > 0 {
+				row.Cells[i] = matrixCell{
+					Open:  true,
+					Price: money(t.Fee),
+					Href:  fmt.Sprintf("%s/teetime/%s%s?game=%d", base, day.Format("20060102"), strings.Replace(t.Time, ":", "", 1), i),
+				}
+			}
+		}
+	}
+
+	// keys start with the 24-hour time so they sort into tee time order
+	sort.Strings(order)
+	for _, key := range order {
+		page.Rows = append(page.Rows, rows[key])
+	}
+	return matrixTemplate.Execute(w, page)
+}
+
+// Helper function to find the lowest fee among the bookable times
+func cheapest(times []TeeTime) string {
+	low := 0.0
+	for _, t := range times {
+		if t.Spots > 0 && t.Fee > 0 && (low == 0 || t.Fee < low) {
+			low = t.Fee
+		}
+	}
+	return money(low)
+}
+
+// Helper function to format a fee like "$49.00", or nothing for 0
+func money(fee float64) string {
+	if fee <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("$%.2f", fee)
+}
+
+// Helper function to describe the spots the way Quick18 does
+func players(spots int) string {
+	if spots <= 1 {
+		return "1 player"
+	}
+	return fmt.Sprintf("1 to %d players", spots)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package fetch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheTTL is how long a cached page is used before it is fetched again
+const DefaultCacheTTL = 10 * time.Minute
+
+// Cache keeps downloaded pages on disk so running the tool again shortly
+// after doesn't fetch everything twice, and so the last pages seen can be
+// shown offline. Pages are stored one file per URL.
+type Cache struct {
+	Dir string
+	TTL time.Duration // pages older than this are fetched again (0 = always)
+}
+
+// cacheEntry is one page as saved on disk
+type cacheEntry struct {
+	URL       string    `json:"url"`
+	FinalURL  string    `json:"final_url"` // after redirects
+	FetchedAt time.Time `json:"fetched_at"`
+	Body      string    `json:"body"`
+}
+
+// OfflineError means a page was needed in offline mode but has never been
+// cached.
+type OfflineError struct {
+	URL string
+}
+
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("%s isn't cached and can't be fetched offline", e.URL)
+}
+
+// NewCache returns a cache that stores pages in dir.
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{Dir: dir, TTL: ttl}
+}
+
+// Helper function to get the file a URL is cached in
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// get returns the cached copy of a URL, if there is one
+func (c *Cache) get(key string) (cacheEntry, bool) {
+	var e cacheEntry
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return e, false
+	}
+	if err := json.Unmarshal(data, &e); err != nil || e.URL != key {
+		return e, false
+	}
+	return e, true
+}
+
+// put saves a page. The file is written under a temp name and renamed so a
+// crash never leaves half a page behind.
+func (c *Cache) put(e cacheEntry) error {
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.Dir, ".page-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(e.URL))
+}
+
+// Prune deletes cached pages older than maxAge, so the cache doesn't keep
+// every day ever searched.
+func (c *Cache) Prune(maxAge time.Duration) error {
+	entries, err := os.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	for _, de := range entries {
+		info, err := de.Info()
+		if err != nil || de.IsDir() {
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			_ = os.Remove(filepath.Join(c.Dir, de.Name()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.URL.Path == "/blocked" {
+			_, _ = w.Write([]byte(`<html><head><title>Just a moment...</title></head></html>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body><a href="next">next</a></body></html>`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// Helper to make a new fetcher (a new search) sharing the cache
+	search := func(ttl time.Duration, offline bool) *Fetcher {
+		f := New()
+		f.Delay = 0
+		f.Cache = NewCache(dir, ttl)
+		f.Offline = offline
+		return f
+	}
+
+	t.Run("Fresh pages are reused", func(t *testing.T) {
+		_, err := search(time.Hour, false).Document(ctx, srv.URL+"/cal")
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+		f := search(time.Hour, false)
+		doc, err := f.Document(ctx, srv.URL+"/cal")
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits), "a page within the TTL should come from disk")
+		assert.Equal(t, srv.URL+"/cal", doc.Url.String(), "cached pages keep their URL for resolving links")
+		assert.False(t, f.CachedSince().IsZero())
+	})
+
+	t.Run("Stale pages are fetched again", func(t *testing.T) {
+		f := search(0, false)
+		_, err := f.Document(ctx, srv.URL+"/cal")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+		assert.True(t, f.CachedSince().IsZero(), "nothing came from the cache")
+	})
+
+	t.Run("Offline uses old pages", func(t *testing.T) {
+		f := search(0, true)
+		_, err := f.Document(ctx, srv.URL+"/cal")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&hits), "offline should never go to the site")
+		assert.WithinDuration(t, time.Now(), f.CachedSince(), time.Minute)
+
+		var offline *OfflineError
+		_, err = f.Document(ctx, srv.URL+"/never-seen")
+		assert.ErrorAs(t, err, &offline)
+	})
+
+	t.Run("Bot checks are not cached", func(t *testing.T) {
+		var blocked *BlockedError
+		_, err := search(time.Hour, false).Document(ctx, srv.URL+"/blocked")
+		require.ErrorAs(t, err, &blocked)
+
+		_, err = search(time.Hour, true).Document(ctx, srv.URL+"/blocked")
+		var offline *OfflineError
+		assert.ErrorAs(t, err, &offline)
+	})
+}
+
+func TestCachePrune(t *testing.T) {
+	dir := t.TempDir()
+	c := NewCache(dir, time.Hour)
+	require.NoError(t, c.put(cacheEntry{URL: "https://a.test/old", FinalURL: "https://a.test/old", FetchedAt: time.Now()}))
+	require.NoError(t, c.put(cacheEntry{URL: "https://a.test/new", FinalURL: "https://a.test/new", FetchedAt: time.Now()}))
+
+	old := time.Now().Add(-8 * 24 * time.Hour)
+	require.NoError(t, os.Chtimes(c.path("https://a.test/old"), old, old))
+
+	require.NoError(t, c.Prune(7*24*time.Hour))
+	_, ok := c.get("https://a.test/old")
+	assert.False(t, ok, "pages past the max age should be deleted")
+	_, ok = c.get("https://a.test/new")
+	assert.True(t, ok)
+
+	assert.NoError(t, NewCache(filepath.Join(dir, "missing"), time.Hour).Prune(time.Hour), "no cache yet is fine")
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package fetch
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// NetworkError means the booking site couldn't be reached at all (DNS, a
+// refused connection, a dropped response and so on).
+type NetworkError struct {
+	URL string
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("network error fetching %s: %v", e.URL, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+// StatusError means the site answered with a status other than 2xx.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string // e.g. "500 Internal Server Error"
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned %s", e.URL, e.Status)
+}
+
+// BlockedError means the site refused to show the page to a scraper, either
+// with a 403/429 or with a captcha or bot check instead of the page.
+type BlockedError struct {
+	URL    string
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("blocked by %s (%s)", e.URL, e.Reason)
+}
+
+// PageError means the page downloaded fine but doesn't look like what the
+// scraper expects, usually because the booking site has changed its layout.
+// Missing lists the selectors that no longer match anything, which is what
+// tells a broken scraper apart from a fully booked day.
+type PageError struct {
+	URL     string
+	Reason  string
+	Missing []string
+}
+
+func (e *PageError) Error() string {
+	if len(e.Missing) > 0 {
+		return fmt.Sprintf("page structure not recognised at %s: %s, no match for %s", e.URL, e.Reason, strings.Join(e.Missing, ", "))
+	}
+	return fmt.Sprintf("page structure not recognised at %s: %s", e.URL, e.Reason)
+}
+
+// CheckLayout makes sure every selector matches at least one element in doc.
+// Scrapers call it with the elements they rely on being there even when
+// nothing is available, and get a *PageError naming the ones that are
+// missing, or nil if the layout looks as expected.
+func CheckLayout(doc *goquery.Document, selectors ...string) error {
+	var missing []string
+	for _, sel := range selectors {
+		if doc.Find(sel).Length() == 0 {
+			missing = append(missing, sel)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	pageURL := ""
+	if doc.Url != nil {
+		pageURL = doc.Url.String()
+	}
+	return &PageError{URL: pageURL, Reason: "layout changed", Missing: missing}
+}
+
+// Helper function to turn a bad status into a BlockedError or StatusError
+func statusError(pageURL string, resp *http.Response) error {
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		return &BlockedError{URL: pageURL, Reason: resp.Status}
+	}
+	return &StatusError{URL: pageURL, StatusCode: resp.StatusCode, Status: resp.Status}
+}
+
+// Elements only found on bot check pages. A captcha widget on its own isn't
+// enough, since normal MiClub pages carry an invisible reCAPTCHA for logging
+// in, and pages can mention "captcha" in scripts and styles.
+var challengeSelectors = []string{
+	"form#challenge-form",
+	"#challenge-running",
+	"#cf-challenge-running",
+	"#challenge-stage",
+	"div.cf-browser-verification",
+}
+
+// Helper function to spot a captcha or bot check served in place of the page
+func challengeReason(doc *goquery.Document) string {
+	for _, sel := range challengeSelectors {
+		if doc.Find(sel).Length() > 0 {
+			return "captcha or bot check page"
+		}
+	}
+
+	title := strings.ToLower(strings.TrimSpace(doc.Find("title").First().Text()))
+	if title == "just a moment..." || strings.HasPrefix(title, "attention required") || strings.HasPrefix(title, "access denied") {
+		return "bot check page"
+	}
+	return ""
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+// Package fetch downloads and parses the booking pages for a search.
+//
+// A Fetcher remembers every page it has fetched, so the date and time
+// scrapers can ask for the same URL as often as they like and it is only
+// downloaded once. Make a new Fetcher for each search so results don't go
+// stale.
+//
+// Requests take a context, so a slow site can be given up on (or the whole
+// search cancelled) without waiting for the HTTP client's own timeout.
+//
+// With a Cache set, pages are also kept on disk and reused until they are
+// older than the cache's TTL. In Offline mode only cached pages are used.
+//
+// A Recorder or Replayer can be set as the client's transport to save a
+// search's responses to a directory and play them back later.
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Defaults match the rate limit the scrapers have always used
+const (
+	DefaultDelay       = 1 * time.Second
+	DefaultParallelism = 2
+	DefaultUserAgent   = "Mozilla/5.0 (compatible; TeeTimeFinder)"
+)
+
+// Fetcher downloads pages once per search and rate limits requests per host.
+// It is safe to use from several goroutines.
+type Fetcher struct {
+	Client      *http.Client
+	UserAgent   string
+	Delay       time.Duration // pause after each request before the host gets another
+	Parallelism int           // requests allowed at once per host
+	Cache       *Cache        // optional on-disk cache
+	Offline     bool          // only use cached pages, never the network
+
+	mu     sync.Mutex
+	pages  map[string]*page
+	hosts  map[string]chan struct{}
+	oldest time.Time // when the oldest cached page handed out was fetched
+}
+
+// page is one URL's result, shared by everyone who asks for it
+type page struct {
+	ready chan struct{} // closed once doc/err are set
+	doc   *goquery.Document
+	err   error
+}
+
+// New returns a Fetcher with the default rate limits.
+func New() *Fetcher {
+	return &Fetcher{
+		Client:      &http.Client{Timeout: 30 * time.Second},
+		UserAgent:   DefaultUserAgent,
+		Delay:       DefaultDelay,
+		Parallelism: DefaultParallelism,
+	}
+}
+
+// Document returns the parsed page at rawURL, downloading it only if no
+// earlier call has. Concurrent calls for the same URL share one download.
+// If ctx is done first its error is returned, and a download cut short by
+// ctx isn't remembered so a later caller can try again.
+//
+// Other failures are a *NetworkError, *StatusError, *BlockedError or, when
+// offline, an *OfflineError.
+func (f *Fetcher) Document(ctx context.Context, rawURL string) (*goquery.Document, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %v", rawURL, err)
+	}
+	key := u.String()
+
+	f.mu.Lock()
+	if f.pages == nil {
+		f.pages = make(map[string]*page)
+	}
+	p, ok := f.pages[key]
+	if !ok {
+		p = &page{ready: make(chan struct{})}
+		f.pages[key] = p
+	}
+	f.mu.Unlock()
+
+	if ok {
+		select {
+		case <-p.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if p.err != nil && ctx.Err() == nil && isContextErr(p.err) {
+			// the first caller gave up, not us, so have a go ourselves
+			return f.Document(ctx, rawURL)
+		}
+		return p.doc, p.err
+	}
+
+	p.doc, p.err = f.load(ctx, u)
+	if p.err != nil && isContextErr(p.err) {
+		f.mu.Lock()
+		delete(f.pages, key)
+		f.mu.Unlock()
+	}
+	close(p.ready)
+	return p.doc, p.err
+}
+
+// Helper function to tell a cancelled or timed out request from a real failure
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Fetched reports how many distinct URLs have been requested so far.
+func (f *Fetcher) Fetched() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.pages)
+}
+
+// CachedSince returns when the oldest cached page used so far was fetched,
+// or the zero time if every page came straight from the site.
+func (f *Fetcher) CachedSince() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.oldest
+}
+
+// Helper function to get a page from the cache if it's fresh enough (or we're
+// offline), otherwise from the site, saving it to the cache on the way
+func (f *Fetcher) load(ctx context.Context, u *url.URL) (*goquery.Document, error) {
+	key := u.String()
+
+	if f.Cache != nil {
+		if e, ok := f.Cache.get(key); ok && (f.Offline || time.Since(e.FetchedAt) < f.Cache.TTL) {
+			if doc, err := parsePage(key, e.FinalURL, []byte(e.Body)); err == nil {
+				f.noteCached(e.FetchedAt)
+				return doc, nil
+			}
+		}
+	}
+	if f.Offline {
+		return nil, &OfflineError{URL: key}
+	}
+
+	body, finalURL, err := f.download(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parsePage(key, finalURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Cache != nil {
+		// a cache that can't be written just means fetching again next time
+		_ = f.Cache.put(cacheEntry{URL: key, FinalURL: finalURL, FetchedAt: time.Now(), Body: string(body)})
+	}
+	return doc, nil
+}
+
+// Helper function to remember the oldest cached page handed out
+func (f *Fetcher) noteCached(fetchedAt time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.oldest.IsZero() || fetchedAt.Before(f.oldest) {
+		f.oldest = fetchedAt
+	}
+}
+
+// Helper function to parse a downloaded or cached page. Bot check pages are
+// rejected here so they are never cached either.
+func parsePage(pageURL, finalURL string, body []byte) (*goquery.Document, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", pageURL, err)
+	}
+	// keep the final URL (after redirects) so relative links resolve
+	if doc.Url, err = url.Parse(finalURL); err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %v", finalURL, err)
+	}
+
+	if reason := challengeReason(doc); reason != "" {
+		return nil, &BlockedError{URL: pageURL, Reason: reason}
+	}
+	return doc, nil
+}
+
+// Helper function to do the actual request once a slot for the host is free.
+// It returns the body and the URL it ended up at after any redirects.
+func (f *Fetcher) download(ctx context.Context, u *url.URL) ([]byte, string, error) {
+	slots := f.hostSlots(u.Hostname())
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	}
+	defer func() {
+		// hold the slot for the delay so the host gets a breather
+		if f.Delay > 0 {
+			select {
+			case <-time.After(f.Delay):
+			case <-ctx.Done():
+			}
+		}
+		<-slots
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, "", ctx.Err()
+		}
+		return nil, "", &NetworkError{URL: u.String(), Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, "", statusError(u.String(), resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, "", ctx.Err()
+		}
+		return nil, "", &NetworkError{URL: u.String(), Err: err}
+	}
+	return body, resp.Request.URL.String(), nil
+}
+
+// Helper function to get (or make) the semaphore for a host
+func (f *Fetcher) hostSlots(host string) chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.hosts == nil {
+		f.hosts = make(map[string]chan struct{})
+	}
+	host = strings.ToLower(host)
+	slots, ok := f.hosts[host]
+	if !ok {
+		n := f.Parallelism
+		if n < 1 {
+			n = 1
+		}
+		slots = make(chan struct{}, n)
+		f.hosts[host] = slots
+	}
+	return slots
+}
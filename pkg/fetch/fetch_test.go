@@ -0,0 +1,217 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentFetchesOnce(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		time.Sleep(20 * time.Millisecond) // give the other goroutines time to pile up
+		_, _ = w.Write([]byte("<html><body><p>hello</p></body></html>"))
+	}))
+	defer srv.Close()
+
+	f := New()
+	f.Delay = 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			doc, err := f.Document(context.Background(), srv.URL+"/page?a=1")
+			assert.NoError(t, err)
+			assert.Equal(t, "hello", doc.Find("p").Text())
+		}()
+	}
+	wg.Wait()
+
+	_, err := f.Document(context.Background(), srv.URL+"/page?a=1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits), "the same URL should only be downloaded once")
+
+	_, err = f.Document(context.Background(), srv.URL+"/page?a=2")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits), "a different URL is a different page")
+	assert.Equal(t, 2, f.Fetched())
+
+	// A new Fetcher starts from scratch
+	_, err = New().Document(context.Background(), srv.URL+"/page?a=1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+}
+
+func TestDocumentErrors(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.Error(w, "nope", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	f := New()
+	f.Delay = 0
+
+	_, err := f.Document(context.Background(), srv.URL)
+	var status *StatusError
+	require.ErrorAs(t, err, &status)
+	assert.Equal(t, http.StatusServiceUnavailable, status.StatusCode)
+	assert.Contains(t, err.Error(), "503")
+
+	// Errors are remembered too, so a broken page isn't hammered
+	_, err = f.Document(context.Background(), srv.URL)
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+}
+
+func TestDocumentURLAfterRedirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/teetimes/new", http.StatusFound)
+	})
+	mux.HandleFunc("/teetimes/new", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html></html>"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	f := New()
+	f.Delay = 0
+
+	doc, err := f.Document(context.Background(), srv.URL+"/old")
+	require.NoError(t, err)
+	assert.Equal(t, "/teetimes/new", doc.Url.Path, "relative links should resolve against the final page")
+}
+
+func TestParallelismPerHost(t *testing.T) {
+	var current, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+		_, _ = w.Write([]byte("<html></html>"))
+	}))
+	defer srv.Close()
+
+	f := New()
+	f.Delay = 0
+	f.Parallelism = 2
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := f.Document(context.Background(), srv.URL+"/page?n="+string(rune('a'+i)))
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2), "no more than Parallelism requests at once")
+}
+
+func TestDocumentContext(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			// the first request hangs until the test is done with it
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		_, _ = w.Write([]byte("<html><body><p>hello</p></body></html>"))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	f := New()
+	f.Delay = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := f.Document(ctx, srv.URL)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second, "a hung page shouldn't wait for the client timeout")
+
+	// A timed out page isn't cached, so the next search tries again
+	doc, err := f.Document(context.Background(), srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", doc.Find("p").Text())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+
+	// An already cancelled context doesn't make a request at all
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	_, err = f.Document(cancelled, srv.URL+"/other")
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+}
+
+func TestDocumentErrorTypes(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/forbidden", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "go away", http.StatusForbidden)
+	})
+	mux.HandleFunc("/slow-down", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	})
+	mux.HandleFunc("/captcha", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Just a moment...</title></head><body><form id="challenge-form"></form></body></html>`))
+	})
+	mux.HandleFunc("/mentions-captcha", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><style>.captcheck_answer_label {}</style></head><body>Tee times<div class="g-recaptcha" data-size="invisible"></div></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	f := New()
+	f.Delay = 0
+	ctx := context.Background()
+
+	var blocked *BlockedError
+	_, err := f.Document(ctx, srv.URL+"/forbidden")
+	assert.ErrorAs(t, err, &blocked, "a 403 means the scraper was blocked")
+	_, err = f.Document(ctx, srv.URL+"/slow-down")
+	assert.ErrorAs(t, err, &blocked, "a 429 means the scraper was blocked")
+	_, err = f.Document(ctx, srv.URL+"/captcha")
+	require.ErrorAs(t, err, &blocked, "a captcha page isn't the page we asked for")
+	assert.Equal(t, "captcha or bot check page", blocked.Reason)
+
+	_, err = f.Document(ctx, srv.URL+"/mentions-captcha")
+	assert.NoError(t, err, "a login captcha on a normal page isn't a bot check")
+
+	// Nothing listening on the port
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadURL := dead.URL
+	dead.Close()
+	var network *NetworkError
+	_, err = f.Document(ctx, deadURL)
+	assert.ErrorAs(t, err, &network)
+}
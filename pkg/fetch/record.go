@@ -0,0 +1,219 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package fetch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ManifestFile lists every response in a recording directory
+const ManifestFile = "recording.json"
+
+// Response headers worth keeping, the rest only add noise to the fixtures
+var recordedHeaders = []string{"Content-Type", "Location"}
+
+// Recording is the manifest of a recording directory. Each response body is
+// saved next to it as a plain HTML file so fixtures are easy to read and diff.
+type Recording struct {
+	Responses []RecordedResponse `json:"responses"`
+}
+
+// RecordedResponse is one response from a recorded search.
+type RecordedResponse struct {
+	URL    string            `json:"url"`
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	File   string            `json:"file"`
+}
+
+// Recorder is an http.RoundTripper that passes requests on to Base and saves
+// every response it gets back into Dir, for replaying later with a Replayer.
+type Recorder struct {
+	Base http.RoundTripper
+	Dir  string
+
+	mu        sync.Mutex
+	recording Recording
+}
+
+// NewRecorder returns a Recorder that saves into dir. If dir already has a
+// recording, new responses are added to it (replacing any for the same URL).
+func NewRecorder(dir string, base http.RoundTripper) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	rec, err := readRecording(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Recorder{Base: base, Dir: dir, recording: rec}, nil
+}
+
+// RoundTrip does the request and saves the response, redirects included.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := r.save(req.URL, resp, body); err != nil {
+		return nil, fmt.Errorf("failed to record %s: %v", req.URL, err)
+	}
+	return resp, nil
+}
+
+// Helper function to write one response and the updated manifest
+func (r *Recorder) save(u *url.URL, resp *http.Response, body []byte) error {
+	rr := RecordedResponse{
+		URL:    u.String(),
+		Status: resp.StatusCode,
+		File:   fixtureName(u),
+	}
+	for _, h := range recordedHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			if rr.Header == nil {
+				rr.Header = make(map[string]string)
+			}
+			rr.Header[h] = v
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.WriteFile(filepath.Join(r.Dir, rr.File), body, 0o644); err != nil {
+		return err
+	}
+
+	key := replayKey(u)
+	replaced := false
+	for i, old := range r.recording.Responses {
+		if oldURL, err := url.Parse(old.URL); err == nil && replayKey(oldURL) == key {
+			r.recording.Responses[i] = rr
+			replaced = true
+		}
+	}
+	if !replaced {
+		r.recording.Responses = append(r.recording.Responses, rr)
+	}
+
+	data, err := json.MarshalIndent(r.recording, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.Dir, ManifestFile), append(data, '\n'), 0o644)
+}
+
+// Replayer is an http.RoundTripper that answers every request from a
+// directory made by a Recorder and never touches the network. Requests that
+// weren't recorded fail.
+type Replayer struct {
+	Dir       string
+	responses map[string]RecordedResponse
+}
+
+// NewReplayer loads the recording in dir.
+func NewReplayer(dir string) (*Replayer, error) {
+	rec, err := readRecording(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording in %s: %v", dir, err)
+	}
+
+	responses := make(map[string]RecordedResponse, len(rec.Responses))
+	for _, rr := range rec.Responses {
+		u, err := url.Parse(rr.URL)
+		if err != nil {
+			return nil, fmt.Errorf("bad URL %q in %s: %v", rr.URL, ManifestFile, err)
+		}
+		responses[replayKey(u)] = rr
+	}
+	return &Replayer{Dir: dir, responses: responses}, nil
+}
+
+// RoundTrip serves the recorded response for req's URL.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	rr, ok := r.responses[replayKey(req.URL)]
+	if !ok {
+		return nil, fmt.Errorf("no recording of %s in %s", req.URL, r.Dir)
+	}
+
+	body, err := os.ReadFile(filepath.Join(r.Dir, rr.File))
+	if err != nil {
+		return nil, err
+	}
+
+	header := make(http.Header)
+	for k, v := range rr.Header {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rr.Status, http.StatusText(rr.Status)),
+		StatusCode:    rr.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Helper function to read a recording's manifest
+func readRecording(dir string) (Recording, error) {
+	var rec Recording
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return rec, err
+	}
+	err = json.Unmarshal(data, &rec)
+	return rec, err
+}
+
+// Helper function to match a request to a recording. The query is
+// re-encoded so the order of its parameters doesn't matter.
+func replayKey(u *url.URL) string {
+	k := *u
+	k.Host = strings.ToLower(k.Host)
+	k.RawQuery = k.Query().Encode()
+	k.Fragment = ""
+	return k.String()
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// Helper function to name a response's file after its host and path, with a
+// short hash of the full URL so different dates don't clash
+func fixtureName(u *url.URL) string {
+	slug := strings.Trim(unsafeFileChars.ReplaceAllString(u.Host+u.Path, "_"), "_")
+	if len(slug) > 80 {
+		slug = slug[:80]
+	}
+	sum := sha256.Sum256([]byte(replayKey(u)))
+	return slug + "_" + hex.EncodeToString(sum[:4]) + ".html"
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/calendar", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte("<html><body><p>" + r.URL.Query().Get("date") + "</p></body></html>"))
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/calendar?date=moved", http.StatusFound)
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "oops", http.StatusInternalServerError)
+	})
+	srv := httptest.NewServer(mux)
+
+	dir := filepath.Join(t.TempDir(), "recording")
+	ctx := context.Background()
+
+	// Record a search against the live server
+	recorder, err := NewRecorder(dir, nil)
+	require.NoError(t, err)
+	f := New()
+	f.Delay = 0
+	f.Client.Transport = recorder
+
+	_, err = f.Document(ctx, srv.URL+"/calendar?date=2025-10-16&view=week")
+	require.NoError(t, err)
+	_, err = f.Document(ctx, srv.URL+"/old")
+	require.NoError(t, err)
+	_, err = f.Document(ctx, srv.URL+"/broken")
+	require.Error(t, err)
+
+	// The fixtures are plain HTML files next to the manifest
+	rec, err := readRecording(dir)
+	require.NoError(t, err)
+	assert.Len(t, rec.Responses, 4, "the redirect and where it led are both recorded")
+	body, err := os.ReadFile(filepath.Join(dir, rec.Responses[0].File))
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "<p>2025-10-16</p>")
+
+	// Replay with the site gone
+	srv.Close()
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+	f = New()
+	f.Delay = 0
+	f.Client.Transport = replayer
+
+	doc, err := f.Document(ctx, srv.URL+"/calendar?view=week&date=2025-10-16")
+	require.NoError(t, err, "the order of the query shouldn't matter")
+	assert.Equal(t, "2025-10-16", doc.Find("p").Text())
+
+	doc, err = f.Document(ctx, srv.URL+"/old")
+	require.NoError(t, err)
+	assert.Equal(t, "moved", doc.Find("p").Text())
+	assert.Equal(t, "/calendar", doc.Url.Path, "redirects are replayed too")
+
+	var status *StatusError
+	_, err = f.Document(ctx, srv.URL+"/broken")
+	require.ErrorAs(t, err, &status, "errors are replayed as they happened")
+	assert.Equal(t, http.StatusInternalServerError, status.StatusCode)
+
+	var network *NetworkError
+	_, err = f.Document(ctx, srv.URL+"/calendar?date=2025-10-17")
+	require.ErrorAs(t, err, &network)
+	assert.Contains(t, err.Error(), "no recording of")
+}
+
+func TestRecorderAddsToRecording(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		_, _ = w.Write([]byte("<html></html>"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		recorder, err := NewRecorder(dir, nil)
+		require.NoError(t, err)
+		f := New()
+		f.Delay = 0
+		f.Client.Transport = recorder
+
+		_, err = f.Document(context.Background(), srv.URL+"/a")
+		require.NoError(t, err)
+		if i == 1 {
+			_, err = f.Document(context.Background(), srv.URL+"/b")
+			require.NoError(t, err)
+		}
+	}
+
+	rec, err := readRecording(dir)
+	require.NoError(t, err)
+	assert.Len(t, rec.Responses, 2, "recording the same URL again replaces it")
+	assert.Equal(t, 3, hits)
+
+	_, err = NewReplayer(t.TempDir())
+	assert.Error(t, err, "an empty directory isn't a recording")
+}
@@ -6,40 +6,86 @@
 package miclub
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
 	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
 
-	"github.com/gocolly/colly"
+	"github.com/PuerkitoBio/goquery"
 )
 
+// Elements every calendar page has, even when nothing can be booked. If any
+// are missing the page has changed and the scraper needs updating.
+var calendarLayout = []string{
+	"div.feeGroupRow",
+	"div.feeGroupRow div.row-heading > h3",
+	"div.feeGroupRow div.items-wrapper > div.cell[data-date]",
+}
+
+// Elements every timesheet has. A fully booked day still lists its times,
+// just with every cell taken.
+var timesheetLayout = []string{
+	"div.row-time",
+	"div.row-time div.time-wrapper > h3",
+	"div.row-time div.time-wrapper > h4",
+	"div.row-time div.cell",
+}
+
 // Scrapes the date URL and returns a map of games and their corresponding timeslot URLs
-func ScrapeDates(baseURL string, selectedDate time.Time) (map[string]string, error) {
-	c := colly.NewCollector(
-		colly.Async(true),
-		colly.MaxDepth(1),
-	)
-
-	// Implement rate limiting
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*",
-		Parallelism: 2,
-		Delay:       1 * time.Second,
-	})
+func ScrapeDates(ctx context.Context, f *fetch.Fetcher, baseURL string, selectedDate time.Time) (map[string]string, error) {
+	days, _, err := scrapeCalendar(ctx, f, baseURL, selectedDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// The selected date is always the first column (data-date="0")
+	if games, ok := days[0]; ok {
+		return games, nil
+	}
+	return make(map[string]string), nil
+}
+
+// ScrapeDateRange returns the games for every date from "from" to "to",
+// keyed by date (YYYY-MM-DD). The calendar shows several days per page, so
+// a new page is only fetched once the range runs past the last day shown.
+func ScrapeDateRange(ctx context.Context, f *fetch.Fetcher, baseURL string, from, to time.Time) (map[string]map[string]string, error) {
+	dateToGames := make(map[string]map[string]string)
+	last := to.Format("2006-01-02")
+
+	for start := from; start.Format("2006-01-02") <= last; {
+		days, shown, err := scrapeCalendar(ctx, f, baseURL, start)
+		if err != nil {
+			return nil, err
+		}
+
+		for offset, games := range days {
+			day := start.AddDate(0, 0, offset).Format("2006-01-02")
+			if day <= last {
+				dateToGames[day] = games
+			}
+		}
 
-	// Map to store the row names and their associated timeslot URLs
-	rowNameToTimeslotURL := make(map[string]string)
+		// a page without any day columns still moves us on, otherwise the
+		// same cached page would be fetched forever
+		start = start.AddDate(0, 0, max(shown, 1))
+	}
 
-	dateStr := selectedDate.Format("2006-01-02")
+	return dateToGames, nil
+}
+
+// Helper function to fetch the calendar page starting at startDate
+func scrapeCalendar(ctx context.Context, f *fetch.Fetcher, baseURL string, startDate time.Time) (map[int]map[string]string, int, error) {
+	dateStr := startDate.Format("2006-01-02")
 
 	// Parse the base URL
 	parsedBaseURL, err := url.Parse(baseURL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %v", err)
+		return nil, 0, fmt.Errorf("invalid base URL: %v", err)
 	}
 
 	// Update query parameters
@@ -48,97 +94,121 @@ func ScrapeDates(baseURL string, selectedDate time.Time) (map[string]string, err
 	q.Set("weekends", "false")
 	parsedBaseURL.RawQuery = q.Encode()
 
-	// Visit the URL
-	err = c.Visit(parsedBaseURL.String())
+	doc, err := f.Document(ctx, parsedBaseURL.String())
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	return parseCalendar(doc, parsedBaseURL)
+}
+
+// Helper function to read one calendar page. Games are keyed by the column
+// offset from the page's start date (data-date="0", "1", ...) and the number
+// of columns on the page is returned so callers know where it ends.
+func parseCalendar(doc *goquery.Document, calendarURL *url.URL) (map[int]map[string]string, int, error) {
+	if err := fetch.CheckLayout(doc, calendarLayout...); err != nil {
+		return nil, 0, err
 	}
 
-	// Keep a copy of the parsed base URL for constructing timeslot URLs
-	baseURLCopy := *parsedBaseURL
+	// Map to store each day's row names and their associated timeslot URLs
+	dayToGames := make(map[int]map[string]string)
+	shown := 0
 
-	// Cycle through the feeGroupRow to capture each game's type and available timeslot
-	c.OnHTML("div.feeGroupRow", func(e *colly.HTMLElement) {
+	// Cycle through the feeGroupRow to capture each game's type and available timeslots
+	doc.Find("div.feeGroupRow").Each(func(_ int, row *goquery.Selection) {
 		// Extract the row heading (game type)
-		rowHeading := e.DOM.Find("div.row-heading > h3").Text()
+		rowHeading := row.Find("div.row-heading > h3").Text()
 		rowHeading = strings.TrimSpace(rowHeading)
 
 		if rowHeading == "" {
 			return
 		}
 
-		// Find the cell corresponding to the selected date (data-date="0")
-		cell := e.DOM.Find("div.items-wrapper > div.cell[data-date='0']")
-		if cell.Length() == 0 {
-			return
-		}
+		// Each cell is one day, starting at the selected date (data-date="0")
+		row.Find("div.items-wrapper > div.cell").Each(func(_ int, cell *goquery.Selection) {
+			offset, err := strconv.Atoi(cell.AttrOr("data-date", ""))
+			if err != nil || offset < 0 {
+				return
+			}
+			if offset+1 > shown {
+				shown = offset + 1
+			}
 
-		// Check if the cell is available (i.e., does not contain "Not Available")
-		cellText := strings.TrimSpace(cell.Text())
+			// Check if the cell is available (i.e., does not contain "Not Available")
+			cellText := strings.TrimSpace(cell.Text())
 
-		if strings.Contains(strings.ToLower(cellText), "not available") ||
-			strings.Contains(strings.ToLower(cellText), "no bookings available") ||
-			cellText == "" {
-			return
-		}
+			if strings.Contains(strings.ToLower(cellText), "not available") ||
+				strings.Contains(strings.ToLower(cellText), "no bookings available") ||
+				cellText == "" {
+				return
+			}
 
-		// Extract the "onclick" attribute for the timeslot URL construction
-		onclickAttr, exists := cell.Attr("onclick")
-		if exists && strings.Contains(onclickAttr, "redirectToTimesheet") {
-			// Extract the feeGroupId and selectedDate from the JavaScript function call
-			timeslotURL := constructTimeslotURL(&baseURLCopy, onclickAttr)
-			if timeslotURL != "" {
-				// Store the row heading and its corresponding timeslot URL
-				rowNameToTimeslotURL[rowHeading] = timeslotURL
+			// Extract the "onclick" attribute for the timeslot URL construction
+			onclickAttr, exists := cell.Attr("onclick")
+			if exists && strings.Contains(onclickAttr, "redirectToTimesheet") {
+				// Extract the feeGroupId and selectedDate from the JavaScript function call
+				timeslotURL := constructTimeslotURL(calendarURL, onclickAttr)
+				if timeslotURL != "" {
+					// Store the row heading and its corresponding timeslot URL
+					if dayToGames[offset] == nil {
+						dayToGames[offset] = make(map[string]string)
+					}
+					dayToGames[offset][rowHeading] = timeslotURL
+				}
 			}
-		}
+		})
 	})
 
-	c.OnError(func(_ *colly.Response, err error) {
-		log.Println("Error:", err)
-	})
+	// Cells are there but none has a day offset we can read, so the columns
+	// can't be matched to dates
+	if shown == 0 {
+		return nil, 0, &fetch.PageError{URL: calendarURL.String(), Reason: "layout changed, data-date isn't a day offset", Missing: []string{calendarLayout[2]}}
+	}
 
-	c.Wait()
-	return rowNameToTimeslotURL, nil
+	return dayToGames, shown, nil
 }
 
-func ScrapeTimes(url string) (map[string][]shared.TeeTimeSlot, error) {
-	c := colly.NewCollector(
-		colly.Async(true),
-		colly.MaxDepth(1),
-	)
-
-	// Implement rate limiting
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*",
-		Parallelism: 2,
-		Delay:       1 * time.Second,
-	})
+func ScrapeTimes(ctx context.Context, f *fetch.Fetcher, url string) (map[string][]shared.TeeTimeSlot, error) {
+	doc, err := f.Document(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return parseTimesheet(doc)
+}
+
+// Helper function to read the available times off a timesheet page, keyed by
+// layout (course configuration)
+func parseTimesheet(doc *goquery.Document) (map[string][]shared.TeeTimeSlot, error) {
+	if err := fetch.CheckLayout(doc, timesheetLayout...); err != nil {
+		return nil, err
+	}
 
 	// Stores the available times
 	layoutToTimes := make(map[string][]shared.TeeTimeSlot)
 
-	c.OnHTML("div.row-time", func(e *colly.HTMLElement) {
+	doc.Find("div.row-time").Each(func(_ int, row *goquery.Selection) {
 
 		// Extract the time
-		time := e.ChildText("div.time-wrapper > h3")
+		time := row.Find("div.time-wrapper > h3").Text()
 		time = strings.TrimSpace(time)
 
 		// Extract the layout (course configuration)
-		layout := e.ChildText("div.time-wrapper > h4")
+		layout := row.Find("div.time-wrapper > h4").Text()
 		layout = strings.TrimSpace(layout)
 
 		if layout == "" || time == "" {
 			return
 		}
 
-		availableSlots := e.DOM.Find("div.cell.cell-available").Length()
+		availableSlots := row.Find("div.cell.cell-available").Length()
 
 		// Only include times with available slots
 		if availableSlots > 0 {
 			timeSlot := shared.TeeTimeSlot{
 				Time:           time,
 				AvailableSpots: availableSlots,
+				Fees:           parseFees(row.Find("div.fees-wrapper li")),
+				BookingURL:     rowBookingURL(doc.Url, row.AttrOr("data-value", "")),
 			}
 
 			// Add this timeSlot to the layout
@@ -146,18 +216,40 @@ func ScrapeTimes(url string) (map[string][]shared.TeeTimeSlot, error) {
 		}
 	})
 
-	c.OnError(func(_ *colly.Response, err error) {
-		log.Println("Error: ", err)
-	})
+	return layoutToTimes, nil
+}
 
-	err := c.Visit(url)
-	if err != nil {
-		return nil, err
-	}
+// Helper function to read the green fees listed beside a timesheet row
+// e.g. <li><span class="price">$30.00</span> Weekday 9H Peak</li>
+func parseFees(items *goquery.Selection) []shared.Fee {
+	var fees []shared.Fee
+	items.Each(func(_ int, li *goquery.Selection) {
+		priceSel := li.Find("span.price")
+		price := priceSel.Text()
 
-	c.Wait()
-	return layoutToTimes, nil
+		// The label is whatever text is left once the price is removed
+		label := strings.Replace(li.Text(), price, "", 1)
 
+		if fee, ok := shared.ParseFee(price, label); ok {
+			fees = append(fees, fee)
+		}
+	})
+	return fees
+}
+
+// Helper function to link straight to a timesheet row. MiClub has no per-row
+// booking page, so the row id is added as a fragment (e.g. #row-1502943956)
+// which the browser scrolls to.
+func rowBookingURL(timesheetURL *url.URL, rowID string) string {
+	if timesheetURL == nil {
+		return ""
+	}
+	u := *timesheetURL
+	rowID = strings.TrimSpace(rowID)
+	if rowID != "" {
+		u.Fragment = "row-" + rowID
+	}
+	return u.String()
 }
 
 // Helper function to construct the full timeslot URL based on the onclick attribute
@@ -6,21 +6,48 @@
 package miclub
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 var runOnline = flag.Bool("online", false, "run online tests that hit the live MiClub sites")
 
+// Helper function to get a fresh Fetcher without the polite delay, which
+// only slows the offline tests down
+func testFetcher() *fetch.Fetcher {
+	f := fetch.New()
+	f.Delay = 0
+	return f
+}
+
+// Helper function to serve the same HTML page at every path, closed when
+// the test ends
+func servePage(t *testing.T, html string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(html))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
 func TestScrapeDates_Online(t *testing.T) {
 	if !*runOnline {
 		t.Skip("online test disabled; run with: go test -args -online")
@@ -51,7 +78,7 @@ func TestScrapeDates_Online(t *testing.T) {
 			selectedDate := time.Now().AddDate(0, 0, 3)
 
 			// Run ScrapeDates
-			results, err := ScrapeDates(c.url, selectedDate)
+			results, err := ScrapeDates(context.Background(), testFetcher(), c.url, selectedDate)
 
 			// Validate Results
 			assert.NoError(t, err, "ScrapeDates should succeed against the live site")
@@ -133,7 +160,7 @@ func TestScrapeDates_Offline(t *testing.T) {
 			base.RawQuery = q.Encode()
 
 			// Run ScrapeDates
-			results, scrapeErr := ScrapeDates(base.String(), selectedDate)
+			results, scrapeErr := ScrapeDates(context.Background(), testFetcher(), base.String(), selectedDate)
 
 			// Validate Results
 			assert.NoError(t, scrapeErr, "ScrapeDates should succeed against the served snapshot")
@@ -160,6 +187,82 @@ func TestScrapeDates_Offline(t *testing.T) {
 	}
 }
 
+func TestScrapeDateRange_Offline(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile(filepath.Join("testdata", "collier_park_dates.html"))
+	require.NoError(t, err, "failed to read local html file")
+
+	// Count calendar requests so we know when a new page was fetched
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(html)
+	}))
+	defer srv.Close()
+
+	base := srv.URL + "/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000"
+
+	// The snapshot starts on 2025-09-27 and shows six days
+	from, err := time.Parse("2006-01-02", "2025-09-27")
+	require.NoError(t, err)
+
+	t.Run("Range within one page", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+
+		results, err := ScrapeDateRange(context.Background(), testFetcher(), base, from, from.AddDate(0, 0, 2))
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits), "one calendar page covers the whole range")
+
+		for _, day := range []string{"2025-09-27", "2025-09-28", "2025-09-29"} {
+			require.NotEmpty(t, results[day], "expected games on %s", day)
+		}
+		assert.NotContains(t, results, "2025-09-30", "dates after the range should be dropped")
+
+		// Each game links to the timesheet for its own day
+		for day, games := range results {
+			for game, ts := range games {
+				u, err := url.Parse(ts)
+				require.NoError(t, err)
+				assert.Equal(t, day, u.Query().Get("selectedDate"), "timesheet for %s should be on %s", game, day)
+			}
+		}
+	})
+
+	t.Run("Range past the last day shown", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+
+		_, err := ScrapeDateRange(context.Background(), testFetcher(), base, from, from.AddDate(0, 0, 8))
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&hits), "a second page should be fetched after six days")
+	})
+}
+
+func TestScrapeDateRange_DatedColumns(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile(filepath.Join("testdata", "collier_park_dates.html"))
+	require.NoError(t, err, "failed to read local html file")
+
+	// Day columns keyed by date rather than by offset from the start
+	dated := string(html)
+	for i := 0; i < 6; i++ {
+		dated = strings.ReplaceAll(dated, fmt.Sprintf(`data-date="%d"`, i), fmt.Sprintf(`data-date="2025-09-%d"`, 27+i))
+	}
+	srv := servePage(t, dated)
+
+	from, err := time.Parse("2006-01-02", "2025-09-27")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = ScrapeDateRange(ctx, testFetcher(), srv.URL+"/guests/bookings/ViewPublicCalendar.msp", from, from.AddDate(0, 0, 2))
+	require.NotErrorIs(t, err, context.DeadlineExceeded, "the range should finish rather than refetch the same page")
+	var pageErr *fetch.PageError
+	require.ErrorAs(t, err, &pageErr)
+}
+
 func TestScrapeTimes_Online(t *testing.T) {
 	if !*runOnline {
 		t.Skip("online test disabled; run with: go test -args -online")
@@ -190,7 +293,7 @@ func TestScrapeTimes_Online(t *testing.T) {
 			selectedDate := time.Now().AddDate(0, 0, 3)
 
 			// ScrapeDates to get one or more timesheet URLs (with correct feeGroupId, selectedDate, etc.)
-			dateResults, err := ScrapeDates(cse.calendarURL, selectedDate)
+			dateResults, err := ScrapeDates(context.Background(), testFetcher(), cse.calendarURL, selectedDate)
 			assert.NoError(t, err, "ScrapeDates should succeed against the live site")
 			require.NotNil(t, dateResults, "dates results map should not be nil")
 
@@ -218,7 +321,7 @@ func TestScrapeTimes_Online(t *testing.T) {
 			assert.NotEmpty(t, q.Get("feeGroupId"), "feeGroupId should be present in query")
 
 			// ScrapeTimes using the public timesheet URL
-			timeResults, scrapeErr := ScrapeTimes(timesheetURL)
+			timeResults, scrapeErr := ScrapeTimes(context.Background(), testFetcher(), timesheetURL)
 
 			// Validate results
 			assert.NoError(t, scrapeErr, "ScrapeTimes should succeed against the live timesheet")
@@ -302,7 +405,7 @@ func TestScrapeTimes_Offline(t *testing.T) {
 			base.RawQuery = q.Encode()
 
 			// Run ScrapeTimes against the served snapshot
-			results, scrapeErr := ScrapeTimes(base.String())
+			results, scrapeErr := ScrapeTimes(context.Background(), testFetcher(), base.String())
 
 			// Validate Results
 			assert.NoError(t, scrapeErr, "ScrapeTimes should succeed against the served snapshot")
@@ -321,12 +424,77 @@ func TestScrapeTimes_Offline(t *testing.T) {
 
 					// Only available slots should be included by ScrapeTimes
 					assert.Greater(t, slot.AvailableSpots, 0, "available spots should be > 0")
+
+					// Every row in the snapshots lists its green fees
+					require.NotEmpty(t, slot.Fees, "timeslot should include its green fees")
+					for _, fee := range slot.Fees {
+						assert.Equal(t, "$", fee.Currency)
+						assert.Greater(t, fee.Amount, 0.0, "fee amount should be > 0")
+						assert.NotEmpty(t, fee.Label, "fee should carry its label")
+					}
 				}
 			}
 		})
 	}
 }
 
+func TestScrapeTimes_Fees(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile(filepath.Join("testdata", "collier_park_timesheet.html"))
+	require.NoError(t, err, "failed to read local html file")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(html)
+	}))
+	defer srv.Close()
+
+	results, err := ScrapeTimes(context.Background(), testFetcher(), srv.URL+"/guests/bookings/ViewPublicTimesheet.msp")
+	require.NoError(t, err)
+
+	var found []shared.Fee
+	for _, slots := range results {
+		for _, slot := range slots {
+			found = append(found, slot.Fees...)
+		}
+	}
+
+	assert.Contains(t, found, shared.Fee{Amount: 30, Currency: "$", Label: "Weekday 9H Peak"})
+	assert.Contains(t, found, shared.Fee{Amount: 23.5, Currency: "$", Label: "Weekday 9H Peak - Concession", Concession: true})
+}
+
+func TestScrapeTimes_BookingURL(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile(filepath.Join("testdata", "collier_park_timesheet.html"))
+	require.NoError(t, err, "failed to read local html file")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(html)
+	}))
+	defer srv.Close()
+
+	timesheetURL := srv.URL + "/guests/bookings/ViewPublicTimesheet.msp?feeGroupId=1500323733&selectedDate=2025-09-30"
+	results, err := ScrapeTimes(context.Background(), testFetcher(), timesheetURL)
+	require.NoError(t, err)
+
+	var urls []string
+	for _, slots := range results {
+		for _, slot := range slots {
+			u, perr := url.Parse(slot.BookingURL)
+			require.NoError(t, perr, "booking URL should parse")
+			assert.Equal(t, "1500323733", u.Query().Get("feeGroupId"), "booking URL should stay on the timesheet")
+			assert.Regexp(t, `^row-\d+$`, u.Fragment, "booking URL should point at the row")
+			urls = append(urls, slot.BookingURL)
+		}
+	}
+
+	// Row 1502943956 is the first available row in the snapshot
+	assert.Contains(t, urls, timesheetURL+"#row-1502943956")
+}
+
 func TestConstructTimeslotURL(t *testing.T) {
 	t.Parallel()
 
@@ -407,3 +575,138 @@ func TestConstructTimeslotURL(t *testing.T) {
 	}
 
 }
+
+func TestProviderDetect(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{"MiClub hosted domain", "https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000", true},
+		{"Club domain with MiClub pages", "https://bookings.collierparkgolf.com.au/guests/bookings/ViewPublicCalendar.msp", true},
+		{"Quick18 URL", "https://springs.quick18.com/teetimes/searchmatrix", false},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := url.Parse(c.url)
+			require.NoError(t, err)
+			assert.Equal(t, c.want, Provider{}.Detect(u))
+		})
+	}
+}
+
+func TestProviderNormaliseURL(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"Already clean", "https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000", "https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000"},
+		{"Date from the browser", "https://Bookings.CollierParkGolf.com.au/guests/bookings/ViewPublicCalendar.msp?selectedDate=2025-02-11&weekends=false&booking_resource_id=3000000#top", "https://bookings.collierparkgolf.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000"},
+		{"Timesheet page", "https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicTimesheet.msp?bookingResourceId=3000000&selectedDate=2025-02-11&feeGroupId=1500000000", "https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp?booking_resource_id=3000000"},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := url.Parse(c.url)
+			require.NoError(t, err)
+			assert.Equal(t, c.want, Provider{}.NormaliseURL(u))
+		})
+	}
+}
+
+func TestScrapeDates_Timeout(t *testing.T) {
+	t.Parallel()
+
+	// A course site that never answers
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	results, err := ScrapeDates(ctx, testFetcher(), srv.URL+"/guests/bookings/ViewPublicCalendar.msp", time.Now())
+	require.ErrorIs(t, err, context.DeadlineExceeded, "a hung site should fail rather than look like it has no games")
+	assert.Nil(t, results)
+}
+
+func TestScrapeUnrecognisedPage(t *testing.T) {
+	t.Parallel()
+
+	// A page that loads fine but isn't a MiClub calendar or timesheet
+	srv := servePage(t, "<html><body><h1>We've moved!</h1></body></html>")
+
+	var pageErr *fetch.PageError
+
+	_, err := ScrapeDates(context.Background(), testFetcher(), srv.URL+"/guests/bookings/ViewPublicCalendar.msp", time.Now())
+	require.ErrorAs(t, err, &pageErr, "a changed calendar should fail, not look like no availability")
+	assert.Contains(t, pageErr.Missing, "div.feeGroupRow")
+
+	_, err = ScrapeTimes(context.Background(), testFetcher(), srv.URL+"/guests/bookings/ViewPublicTimesheet.msp")
+	require.ErrorAs(t, err, &pageErr, "a changed timesheet should fail, not look like no availability")
+	assert.Contains(t, pageErr.Missing, "div.row-time")
+}
+
+func TestLayoutChanged(t *testing.T) {
+	t.Parallel()
+
+	dates, err := os.ReadFile(filepath.Join("testdata", "collier_park_dates.html"))
+	require.NoError(t, err, "failed to read local html file")
+	timesheet, err := os.ReadFile(filepath.Join("testdata", "collier_park_timesheet.html"))
+	require.NoError(t, err, "failed to read local html file")
+
+	t.Run("Renamed calendar rows", func(t *testing.T) {
+		srv := servePage(t, strings.ReplaceAll(string(dates), "feeGroupRow", "fee-group-row"))
+
+		_, err := ScrapeDates(context.Background(), testFetcher(), srv.URL+"/guests/bookings/ViewPublicCalendar.msp", time.Now())
+		var pageErr *fetch.PageError
+		require.ErrorAs(t, err, &pageErr)
+		assert.Equal(t, calendarLayout, pageErr.Missing, "every selector under the renamed row should be reported")
+	})
+
+	t.Run("Dates instead of day offsets", func(t *testing.T) {
+		srv := servePage(t, strings.ReplaceAll(string(dates), `data-date="0"`, `data-date="2025-09-27"`))
+		dated := servePage(t, regexp.MustCompile(`data-date="\d+"`).ReplaceAllString(string(dates), `data-date="2025-09-27"`))
+
+		games, err := ScrapeDates(context.Background(), testFetcher(), srv.URL+"/guests/bookings/ViewPublicCalendar.msp", time.Now())
+		require.NoError(t, err, "one odd cell still leaves the other days readable")
+		assert.Empty(t, games, "the first column is the one that couldn't be read")
+
+		_, err = ScrapeDates(context.Background(), testFetcher(), dated.URL+"/guests/bookings/ViewPublicCalendar.msp", time.Now())
+		var pageErr *fetch.PageError
+		require.ErrorAs(t, err, &pageErr, "a calendar we can't date should fail, not look like no games")
+		assert.Equal(t, []string{"div.feeGroupRow div.items-wrapper > div.cell[data-date]"}, pageErr.Missing)
+		assert.Contains(t, err.Error(), "data-date isn't a day offset")
+	})
+
+	t.Run("Renamed time heading", func(t *testing.T) {
+		srv := servePage(t, strings.ReplaceAll(string(timesheet), "time-wrapper", "time-box"))
+
+		_, err := ScrapeTimes(context.Background(), testFetcher(), srv.URL+"/guests/bookings/ViewPublicTimesheet.msp")
+		var pageErr *fetch.PageError
+		require.ErrorAs(t, err, &pageErr)
+		assert.Equal(t, []string{"div.row-time div.time-wrapper > h3", "div.row-time div.time-wrapper > h4"}, pageErr.Missing)
+		assert.Contains(t, err.Error(), "layout changed")
+	})
+
+	t.Run("Fully booked is not a layout change", func(t *testing.T) {
+		srv := servePage(t, strings.ReplaceAll(string(timesheet), "cell-available", "cell-taken"))
+
+		results, err := ScrapeTimes(context.Background(), testFetcher(), srv.URL+"/guests/bookings/ViewPublicTimesheet.msp")
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
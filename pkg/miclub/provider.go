@@ -0,0 +1,79 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package miclub
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/provider"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
+)
+
+func init() {
+	provider.Register(Provider{})
+}
+
+// Provider exposes the MiClub scrapers through the provider.Provider interface.
+type Provider struct{}
+
+func (Provider) Name() string { return "miclub" }
+
+// Detect matches *.miclub.com.au hosts and any site serving the MiClub
+// public booking pages (many clubs use their own domain).
+func (Provider) Detect(courseURL *url.URL) bool {
+	host := strings.ToLower(courseURL.Hostname())
+	if host == "miclub.com.au" || strings.HasSuffix(host, ".miclub.com.au") {
+		return true
+	}
+	return strings.HasPrefix(strings.ToLower(courseURL.Path), "/guests/bookings/")
+}
+
+// NormaliseURL points a timesheet URL back at the calendar and drops the
+// query parameters the scrapers set, keeping booking_resource_id and
+// anything else the club needs. The timesheet calls the course
+// bookingResourceId, which the calendar doesn't know.
+func (Provider) NormaliseURL(courseURL *url.URL) string {
+	u := *courseURL
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+
+	q := u.Query()
+	if strings.HasSuffix(strings.ToLower(u.Path), "/viewpublictimesheet.msp") {
+		u.Path = u.Path[:strings.LastIndex(u.Path, "/")] + "/ViewPublicCalendar.msp"
+		if id := q.Get("bookingResourceId"); id != "" && q.Get("booking_resource_id") == "" {
+			q.Set("booking_resource_id", id)
+		}
+		q.Del("bookingResourceId")
+	}
+
+	for _, key := range []string{"selectedDate", "weekends", "feeGroupId"} {
+		q.Del(key)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func (Provider) ScrapeDates(ctx context.Context, f *fetch.Fetcher, baseURL string, selectedDate time.Time) (map[string]string, error) {
+	return ScrapeDates(ctx, f, baseURL, selectedDate)
+}
+
+func (Provider) ScrapeDateRange(ctx context.Context, f *fetch.Fetcher, baseURL string, from, to time.Time) (map[string]map[string]string, error) {
+	return ScrapeDateRange(ctx, f, baseURL, from, to)
+}
+
+// ScrapeTimes ignores game because every MiClub timesheet URL already
+// belongs to a single fee group.
+func (Provider) ScrapeTimes(ctx context.Context, f *fetch.Fetcher, timeslotURL, _ string) (map[string][]shared.TeeTimeSlot, error) {
+	return ScrapeTimes(ctx, f, timeslotURL)
+}
+
+func (Provider) BookingURL(timeslotURL string) string {
+	return timeslotURL
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+// Package provider defines the interface a booking platform implements and a
+// registry the CLI uses to look platforms up by website type or course URL.
+//
+// Each platform package registers itself from an init function, so supporting
+// a new booking website only needs a new package that is imported for its
+// side effects.
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
+)
+
+// Provider is implemented by every supported booking platform. Pages are
+// downloaded through the Fetcher so a page shared by several games is only
+// fetched once per search. Scrapes stop early with ctx's error if ctx is
+// cancelled or times out.
+type Provider interface {
+	// Name is the website type stored in the config file, e.g. "miclub".
+	Name() string
+
+	// Detect reports whether a course URL belongs to this platform.
+	Detect(courseURL *url.URL) bool
+
+	// NormaliseURL tidies a course URL copied from the browser into the one
+	// to keep in the config, e.g. dropping the date the scrapers set
+	// themselves.
+	NormaliseURL(courseURL *url.URL) string
+
+	// ScrapeDates returns the games available on selectedDate, mapped to the
+	// URL that lists their tee times.
+	ScrapeDates(ctx context.Context, f *fetch.Fetcher, baseURL string, selectedDate time.Time) (map[string]string, error)
+
+	// ScrapeDateRange returns the games available on each date from "from"
+	// to "to", keyed by date (YYYY-MM-DD) and then by game.
+	ScrapeDateRange(ctx context.Context, f *fetch.Fetcher, baseURL string, from, to time.Time) (map[string]map[string]string, error)
+
+	// ScrapeTimes returns the available tee times for game at timeslotURL,
+	// keyed by course layout.
+	ScrapeTimes(ctx context.Context, f *fetch.Fetcher, timeslotURL, game string) (map[string][]shared.TeeTimeSlot, error)
+
+	// BookingURL returns the page a user should open to book a game listed
+	// at timeslotURL.
+	BookingURL(timeslotURL string) string
+}
+
+var (
+	mu        sync.RWMutex
+	providers = make(map[string]Provider)
+)
+
+// Register makes a provider available by its name. It panics if a provider
+// with the same name is registered twice.
+func Register(p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	key := strings.ToLower(p.Name())
+	if _, dup := providers[key]; dup {
+		panic(fmt.Sprintf("provider: Register called twice for %q", p.Name()))
+	}
+	providers[key] = p
+}
+
+// Lookup returns the provider registered under the website type name. The
+// match is case-insensitive so "MiClub" and "miclub" are equivalent.
+func Lookup(name string) (Provider, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	p, ok := providers[strings.ToLower(strings.TrimSpace(name))]
+	return p, ok
+}
+
+// Detect returns the provider whose URL pattern matches courseURL.
+func Detect(courseURL string) (Provider, bool) {
+	u, err := url.Parse(strings.TrimSpace(courseURL))
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+
+	for _, name := range Names() {
+		p, _ := Lookup(name)
+		if p.Detect(u) {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Normalise detects the provider for courseURL and returns it with the
+// tidied up URL.
+func Normalise(courseURL string) (Provider, string, bool) {
+	p, ok := Detect(courseURL)
+	if !ok {
+		return nil, "", false
+	}
+	u, _ := url.Parse(strings.TrimSpace(courseURL))
+	return p, p.NormaliseURL(u), true
+}
+
+// Names returns the registered provider names in alphabetical order.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
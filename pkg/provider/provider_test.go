@@ -0,0 +1,97 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package provider
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider struct {
+	name string
+	host string
+}
+
+func (f fakeProvider) Name() string { return f.name }
+
+func (f fakeProvider) Detect(u *url.URL) bool {
+	return strings.EqualFold(u.Hostname(), f.host)
+}
+
+func (fakeProvider) NormaliseURL(u *url.URL) string { return u.String() }
+
+func (fakeProvider) ScrapeDates(context.Context, *fetch.Fetcher, string, time.Time) (map[string]string, error) {
+	return nil, nil
+}
+
+func (fakeProvider) ScrapeDateRange(context.Context, *fetch.Fetcher, string, time.Time, time.Time) (map[string]map[string]string, error) {
+	return nil, nil
+}
+
+func (fakeProvider) ScrapeTimes(context.Context, *fetch.Fetcher, string, string) (map[string][]shared.TeeTimeSlot, error) {
+	return nil, nil
+}
+
+func (fakeProvider) BookingURL(u string) string { return u }
+
+func TestRegistry(t *testing.T) {
+	Register(fakeProvider{name: "FakeClub", host: "bookings.fakeclub.test"})
+
+	t.Run("Lookup is case-insensitive", func(t *testing.T) {
+		p, ok := Lookup("fakeclub")
+		require.True(t, ok)
+		assert.Equal(t, "FakeClub", p.Name())
+
+		_, ok = Lookup("  FAKECLUB ")
+		assert.True(t, ok)
+	})
+
+	t.Run("Unknown type", func(t *testing.T) {
+		_, ok := Lookup("golfnow")
+		assert.False(t, ok)
+	})
+
+	t.Run("Detect by URL", func(t *testing.T) {
+		p, ok := Detect("https://bookings.fakeclub.test/teetimes")
+		require.True(t, ok)
+		assert.Equal(t, "FakeClub", p.Name())
+
+		_, ok = Detect("https://example.com/teetimes")
+		assert.False(t, ok)
+
+		_, ok = Detect("not a url")
+		assert.False(t, ok)
+	})
+
+	t.Run("Normalise by URL", func(t *testing.T) {
+		p, u, ok := Normalise("  https://bookings.fakeclub.test/teetimes ")
+		require.True(t, ok)
+		assert.Equal(t, "FakeClub", p.Name())
+		assert.Equal(t, "https://bookings.fakeclub.test/teetimes", u)
+
+		_, _, ok = Normalise("https://example.com/teetimes")
+		assert.False(t, ok)
+	})
+
+	t.Run("Names are listed", func(t *testing.T) {
+		assert.Contains(t, Names(), "fakeclub")
+	})
+
+	t.Run("Duplicate registration panics", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Register(fakeProvider{name: "fakeclub"})
+		})
+	})
+}
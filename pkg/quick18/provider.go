@@ -0,0 +1,85 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package quick18
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/provider"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
+)
+
+func init() {
+	provider.Register(Provider{})
+}
+
+// Provider exposes the Quick18 scrapers through the provider.Provider interface.
+type Provider struct{}
+
+func (Provider) Name() string { return "quick18" }
+
+// Detect matches *.quick18.com hosts and the searchmatrix page itself, even
+// when it's under a path prefix.
+func (Provider) Detect(courseURL *url.URL) bool {
+	host := strings.ToLower(courseURL.Hostname())
+	if host == "quick18.com" || strings.HasSuffix(host, ".quick18.com") {
+		return true
+	}
+	return strings.Contains(strings.ToLower(courseURL.Path), "/teetimes/searchmatrix")
+}
+
+// NormaliseURL points any Quick18 page, or the bare site, at the
+// searchmatrix and drops the teedate the scrapers set. Another page's query
+// means nothing to the searchmatrix so it goes too.
+func (Provider) NormaliseURL(courseURL *url.URL) string {
+	u := *courseURL
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	if i := strings.Index(strings.ToLower(u.Path), "/teetimes/"); i >= 0 {
+		if !strings.EqualFold(u.Path[i:], "/teetimes/searchmatrix") {
+			u.RawQuery = ""
+		}
+		u.Path = u.Path[:i] + "/teetimes/searchmatrix"
+	} else if u.Path == "" || u.Path == "/" {
+		u.Path = "/teetimes/searchmatrix"
+	}
+
+	q := u.Query()
+	q.Del("teedate")
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func (Provider) ScrapeDates(ctx context.Context, f *fetch.Fetcher, baseURL string, selectedDate time.Time) (map[string]string, error) {
+	return ScrapeDates(ctx, f, baseURL, selectedDate)
+}
+
+func (Provider) ScrapeDateRange(ctx context.Context, f *fetch.Fetcher, baseURL string, from, to time.Time) (map[string]map[string]string, error) {
+	return ScrapeDateRange(ctx, f, baseURL, from, to)
+}
+
+// ScrapeTimes keeps only the column for game, since one Quick18 page lists
+// every game for the day side by side. The Fetcher hands back the page
+// ScrapeDates already loaded, so asking for each game costs nothing extra.
+func (Provider) ScrapeTimes(ctx context.Context, f *fetch.Fetcher, timeslotURL, game string) (map[string][]shared.TeeTimeSlot, error) {
+	gameToLayouts, err := ScrapeTimes(ctx, f, timeslotURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if layouts, ok := gameToLayouts[game]; ok {
+		return layouts, nil
+	}
+	return make(map[string][]shared.TeeTimeSlot), nil
+}
+
+func (Provider) BookingURL(timeslotURL string) string {
+	return timeslotURL
+}
@@ -6,18 +6,19 @@
 package quick18
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
 	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/gocolly/colly"
 )
 
 // Duplicate declarations.
@@ -42,40 +43,89 @@ type Timeslot struct {
 	AvailableSpots int
 }
 
-func ScrapeDates(baseURL string, selectedDate time.Time) (map[string]string, error) {
-	// Example: https://springs.quick18.com/teetimes/searchmatrix?teedate=20250211
-	dateStr := selectedDate.Format("20060102") // e.g. "20250211"
-
-	// Parse the base URL to update the "teedate" parameter
-	parsed, err := url.Parse(baseURL)
+// ScrapeDates returns the games with at least one bookable time on
+// selectedDate. A day with nothing free gives an empty map.
+func ScrapeDates(ctx context.Context, f *fetch.Fetcher, baseURL string, selectedDate time.Time) (map[string]string, error) {
+	dateToGames, err := ScrapeDateRange(ctx, f, baseURL, selectedDate, selectedDate)
 	if err != nil {
-		return nil, fmt.Errorf("Invalid base URL: %v", err)
+		return nil, err
 	}
 
-	// Overwrite the "teedate" parameter with the chosen date
-	q := parsed.Query()
-	q.Set("teedate", dateStr)
-	parsed.RawQuery = q.Encode()
+	gameMap := dateToGames[selectedDate.Format("2006-01-02")]
+	if gameMap == nil {
+		gameMap = make(map[string]string)
+	}
+	return gameMap, nil
+}
 
-	finalURL := parsed.String()
+// ScrapeDateRange returns the games with availability on each date from
+// "from" to "to", keyed by date (YYYY-MM-DD). The searchmatrix page only
+// lists one day's times, so every date is its own request. They go through
+// the same Fetcher so the rate limit covers the whole range, and ScrapeTimes
+// reuses the pages later.
+func ScrapeDateRange(ctx context.Context, f *fetch.Fetcher, baseURL string, from, to time.Time) (map[string]map[string]string, error) {
+	var days []time.Time
+	var urls []string
+	last := to.Format("2006-01-02")
+	for day := from; day.Format("2006-01-02") <= last; day = day.AddDate(0, 0, 1) {
+		finalURL, err := matrixURL(baseURL, day)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+		urls = append(urls, finalURL)
+	}
 
-	c := colly.NewCollector(
-		colly.Async(true),
-		colly.MaxDepth(1),
-	)
+	// Fetch the days side by side, the Fetcher keeps it to the host's limit
+	gameMaps := make([]map[string]string, len(days))
+	errs := make([]error, len(days))
+	var wg sync.WaitGroup
+	for i := range days {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			doc, err := f.Document(ctx, urls[i])
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			gameMaps[i], errs[i] = parseAvailableGames(doc, urls[i])
+		}(i)
+	}
+	wg.Wait()
 
-	// Implement rate limiting
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*",
-		Parallelism: 2,
-		Delay:       1 * time.Second,
-	})
+	// running out of time isn't the same as the course having no games
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	// a day that failed means the range can't be trusted, report the first
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dateToGames := make(map[string]map[string]string)
+	for i, day := range days {
+		if len(gameMaps[i]) > 0 {
+			dateToGames[day.Format("2006-01-02")] = gameMaps[i]
+		}
+	}
+	return dateToGames, nil
+}
+
+// Helper function to find which game columns on a searchmatrix page have at
+// least one bookable time. Each game maps to the page's URL.
+func parseAvailableGames(doc *goquery.Document, pageURL string) (map[string]string, error) {
+	if err := checkMatrix(doc); err != nil {
+		return nil, err
+	}
 
-	// Load the page, look for all <th class="matrixHdrSched">, which are the “game type” columns
+	// Look for all <th class="matrixHdrSched">, which are the “game type” columns
 	var schedHeaders []string
-	c.OnHTML("table.matrixTable thead tr", func(h *colly.HTMLElement) {
-		h.ForEach("th.matrixHdrSched", func(_ int, th *colly.HTMLElement) {
-			gameName := strings.TrimSpace(th.Text) // e.g. "9 Holes"
+	doc.Find("table.matrixTable thead tr").Each(func(_ int, tr *goquery.Selection) {
+		tr.Find("th.matrixHdrSched").Each(func(_ int, th *goquery.Selection) {
+			gameName := strings.TrimSpace(th.Text()) // e.g. "9 Holes"
 			if gameName != "" {
 				schedHeaders = append(schedHeaders, gameName)
 			}
@@ -85,9 +135,9 @@ func ScrapeDates(baseURL string, selectedDate time.Time) (map[string]string, err
 	var columnHasAvailability []bool
 
 	// Check the row in <tbody>
-	c.OnHTML("table.matrixTable tbody tr", func(e *colly.HTMLElement) {
+	doc.Find("table.matrixTable tbody tr").Each(func(_ int, tr *goquery.Selection) {
 		// Find all .matrixsched cells in this row:
-		tdList := e.DOM.Find("td.matrixsched")
+		tdList := tr.Find("td.matrixsched")
 		if columnHasAvailability == nil {
 			// Initialise the slice once we know how many columns
 			columnHasAvailability = make([]bool, tdList.Length())
@@ -95,7 +145,7 @@ func ScrapeDates(baseURL string, selectedDate time.Time) (map[string]string, err
 
 		// For each column index i, see if it’s active (.mtrxInactive? no) and has a “Select” link
 		tdList.Each(func(i int, sel *goquery.Selection) {
-			if sel.HasClass("mtrxInactive") {
+			if sel.HasClass("mtrxInactive") || i >= len(columnHasAvailability) {
 				return
 			}
 			selectLinkCount := sel.Find("a.sexybutton.teebutton").Length()
@@ -106,71 +156,101 @@ func ScrapeDates(baseURL string, selectedDate time.Time) (map[string]string, err
 		})
 	})
 
-	c.OnError(func(_ *colly.Response, err error) {
-		log.Println("[Quick18] ScrapeDates error:", err)
-	})
-
-	if err := c.Visit(finalURL); err != nil {
-		return nil, fmt.Errorf("failed to fetch Quick18 date page: %v", err)
-	}
-	c.Wait()
-
 	gameMap := make(map[string]string)
 	for i, header := range schedHeaders {
 		if i < len(columnHasAvailability) && columnHasAvailability[i] {
-			gameMap[header] = finalURL
+			gameMap[header] = pageURL
 		}
 	}
+	return gameMap, nil
+}
+
+// Elements every searchmatrix page with tee times on it has, booked or not.
+// If any are missing the page has changed and the scraper needs updating.
+var matrixLayout = []string{
+	"table.matrixTable",
+	"table.matrixTable th.matrixHdrSched",
+	"table.matrixTable td.mtrxTeeTimes",
+	"table.matrixTable td.matrixPlayers",
+	"table.matrixTable td.matrixsched",
+}
+
+// Helper function to make sure a searchmatrix page has its tee time table.
+// A day without any tee times shows a message in its place, which is fine.
+func checkMatrix(doc *goquery.Document) error {
+	if doc.Find("table.matrixTable").Length() == 0 &&
+		strings.Contains(strings.ToLower(doc.Find("body").Text()), "no tee times") {
+		return nil
+	}
+	return fetch.CheckLayout(doc, matrixLayout...)
+}
 
-	// If no headers were found, fall back to single "All Tee Times"
-	if len(gameMap) == 0 {
-		gameMap["All Tee Times"] = finalURL
+// Helper function to point the searchmatrix URL at a given day
+// Example: https://springs.quick18.com/teetimes/searchmatrix?teedate=20250211
+func matrixURL(baseURL string, day time.Time) (string, error) {
+	// Parse the base URL to update the "teedate" parameter
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("Invalid base URL: %v", err)
 	}
 
-	return gameMap, nil
+	// Overwrite the "teedate" parameter with the chosen date
+	q := parsed.Query()
+	q.Set("teedate", day.Format("20060102"))
+	parsed.RawQuery = q.Encode()
+
+	return parsed.String(), nil
 }
 
-// ScrapeTimes visits the Quick18 "matrixTable" page and extracts timeslots
-func ScrapeTimes(url string) (map[string][]shared.TeeTimeSlot, error) {
-	c := colly.NewCollector(
-		colly.Async(true),
-		colly.MaxDepth(1),
-	)
-
-	// Rate limiting, etc.
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*",
-		Parallelism: 2,
-		Delay:       1 * time.Second,
-	})
+// ScrapeTimes reads the Quick18 "matrixTable" page and extracts timeslots.
+// Results are keyed by game (the column header) and then by layout (the
+// "Course" column, e.g. "Back 9 Morning"), matching the MiClub layouts.
+func ScrapeTimes(ctx context.Context, f *fetch.Fetcher, url string) (map[string]map[string][]shared.TeeTimeSlot, error) {
+	doc, err := f.Document(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return parseMatrix(doc)
+}
+
+// Helper function to read every available tee time off a searchmatrix page
+func parseMatrix(doc *goquery.Document) (map[string]map[string][]shared.TeeTimeSlot, error) {
+	if err := checkMatrix(doc); err != nil {
+		return nil, err
+	}
 
 	// 1) Grab all the column headers (e.g. "9 Holes", "18 Holes", etc.)
-	var columnHeaders []string
-	c.OnHTML("table.matrixTable thead tr", func(h *colly.HTMLElement) {
-		h.ForEach("th.matrixHdrSched", func(_ int, th *colly.HTMLElement) {
-			headerText := strings.TrimSpace(th.Text)
+	// The raw header doubles as the fee label for prices in that column.
+	var columnHeaders, columnLabels []string
+	doc.Find("table.matrixTable thead tr").Each(func(_ int, tr *goquery.Selection) {
+		tr.Find("th.matrixHdrSched").Each(func(_ int, th *goquery.Selection) {
+			headerText := strings.TrimSpace(th.Text())
 			if headerText != "" {
 				normalise := normaliseGameName(headerText)
 				columnHeaders = append(columnHeaders, normalise)
+				columnLabels = append(columnLabels, headerText)
 			}
 		})
 	})
 
-	// 2) Prepare a map from “header text” -> slice of tee times.
-	headerToTimes := make(map[string][]shared.TeeTimeSlot)
+	// 2) Prepare a map from “header text” -> layout -> slice of tee times.
+	headerToTimes := make(map[string]map[string][]shared.TeeTimeSlot)
 
-	// 3) For each body row, parse the time, players, and each sched cell.
-	c.OnHTML("table.matrixTable tbody tr", func(e *colly.HTMLElement) {
+	// 3) For each body row, parse the time, layout, players, and each sched cell.
+	doc.Find("table.matrixTable tbody tr").Each(func(_ int, tr *goquery.Selection) {
 		// Time cell
-		rawTime := strings.TrimSpace(e.ChildText("td.mtrxTeeTimes"))
+		rawTime := strings.TrimSpace(tr.Find("td.mtrxTeeTimes").Text())
 		timeStr := parseTimeCell(rawTime)
 
+		// Course cell, e.g. "Back 9 Morning" (not every site shows it)
+		layout := strings.Join(strings.Fields(tr.Find("td.mtrxCourse").Text()), " ")
+
 		// Players cell
-		playerCell := strings.TrimSpace(e.ChildText("td.matrixPlayers"))
+		playerCell := strings.TrimSpace(tr.Find("td.matrixPlayers").Text())
 		availableSpots := parsePlayers(playerCell)
 
 		// The “sched” cells (one per header)
-		schedCells := e.DOM.Find("td.matrixsched")
+		schedCells := tr.Find("td.matrixsched")
 		schedCells.Each(func(i int, sel *goquery.Selection) {
 			// Make sure we don’t run past columnHeaders
 			if i >= len(columnHeaders) {
@@ -183,8 +263,8 @@ func ScrapeTimes(url string) (map[string][]shared.TeeTimeSlot, error) {
 				return
 			}
 			// Does this cell have a "Select" link?
-			linkCount := sel.Find("a.sexybutton.teebutton").Length()
-			if linkCount == 0 {
+			link := sel.Find("a.sexybutton.teebutton")
+			if link.Length() == 0 {
 				return
 			}
 
@@ -193,22 +273,40 @@ func ScrapeTimes(url string) (map[string][]shared.TeeTimeSlot, error) {
 				Time:           timeStr,
 				AvailableSpots: availableSpots,
 			}
-			headerToTimes[header] = append(headerToTimes[header], slot)
-		})
-	})
+			if href, ok := link.Attr("href"); ok {
+				slot.BookingURL = absoluteURL(doc.Url, href)
+			}
+			if fee, ok := shared.ParseFee(sel.Find("div.mtrxPrice").Text(), columnLabels[i]); ok {
+				slot.Fees = []shared.Fee{fee}
+			}
+			// Without a course column, fall back to the game as the layout
+			slotLayout := layout
+			if slotLayout == "" {
+				slotLayout = header
+			}
 
-	// Handle errors and visit
-	c.OnError(func(_ *colly.Response, err error) {
-		log.Println("[Quick18] Error:", err)
+			if headerToTimes[header] == nil {
+				headerToTimes[header] = make(map[string][]shared.TeeTimeSlot)
+			}
+			headerToTimes[header][slotLayout] = append(headerToTimes[header][slotLayout], slot)
+		})
 	})
-	if err := c.Visit(url); err != nil {
-		return nil, fmt.Errorf("failed to visit Quick18 URL %s: %v", url, err)
-	}
-	c.Wait()
 
 	return headerToTimes, nil
 }
 
+// Helper function to resolve a link on the page against the page's URL
+func absoluteURL(pageURL *url.URL, href string) string {
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return ""
+	}
+	if pageURL == nil {
+		return ref.String()
+	}
+	return pageURL.ResolveReference(ref).String()
+}
+
 // parseTimeCell merges something like "2:30\nPM" into "2:30 PM"
 func parseTimeCell(raw string) string {
 	lines := strings.Split(strings.TrimSpace(raw), "\n")
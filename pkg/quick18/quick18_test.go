@@ -6,21 +6,46 @@
 package quick18
 
 import (
+	"context"
 	"flag"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/fetch"
+	"github.com/Ay1tsMe/TeeTimeFinder/pkg/shared"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 var runOnline = flag.Bool("online", false, "run online tests that hit live Quick18 sites")
 
+// Helper function to get a fresh Fetcher without the polite delay, which
+// only slows the offline tests down
+func testFetcher() *fetch.Fetcher {
+	f := fetch.New()
+	f.Delay = 0
+	return f
+}
+
+// Helper function to serve the same HTML page at every path, closed when
+// the test ends
+func servePage(t *testing.T, html string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(html))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
 func TestScrapeDates_Online(t *testing.T) {
 	if !*runOnline {
 		t.Skip("online test disabled; run with: go test -args -online")
@@ -51,7 +76,7 @@ func TestScrapeDates_Online(t *testing.T) {
 			selectedDate := time.Now().AddDate(0, 0, 3)
 
 			// Run ScrapeDates
-			results, err := ScrapeDates(c.url, selectedDate)
+			results, err := ScrapeDates(context.Background(), testFetcher(), c.url, selectedDate)
 
 			// Validate Results
 			assert.NoError(t, err, "ScrapeDates should succeed against live Quick18 site")
@@ -131,7 +156,7 @@ func TestScrapeDates_Offline(t *testing.T) {
 			base.Path = "/teetimes/searchmatrix"
 
 			// Run ScrapeDates
-			results, scrapeErr := ScrapeDates(base.String(), selectedDate)
+			results, scrapeErr := ScrapeDates(context.Background(), testFetcher(), base.String(), selectedDate)
 
 			assert.NoError(t, scrapeErr, "ScrapeDates should succeed against served snapshot")
 			require.NotNil(t, results, "results map should not be nil")
@@ -155,6 +180,43 @@ func TestScrapeDates_Offline(t *testing.T) {
 	}
 }
 
+func TestScrapeDateRange_Offline(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile(filepath.Join("testdata", "the_springs.html"))
+	require.NoError(t, err, "failed to read local html file")
+
+	// Record which days were requested
+	var mu sync.Mutex
+	teedates := map[string]bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		teedates[r.URL.Query().Get("teedate")] = true
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(html)
+	}))
+	defer srv.Close()
+
+	from, err := time.Parse("2006-01-02", "2025-10-16")
+	require.NoError(t, err)
+
+	results, err := ScrapeDateRange(context.Background(), testFetcher(), srv.URL+"/teetimes/searchmatrix", from, from.AddDate(0, 0, 2))
+	require.NoError(t, err)
+
+	// Quick18 shows one day per page, so each date is fetched once
+	assert.Equal(t, map[string]bool{"20251016": true, "20251017": true, "20251018": true}, teedates)
+
+	for _, day := range []string{"2025-10-16", "2025-10-17", "2025-10-18"} {
+		require.NotEmpty(t, results[day], "expected games on %s", day)
+		for game, ustr := range results[day] {
+			u, err := url.Parse(ustr)
+			require.NoError(t, err)
+			assert.Equal(t, strings.ReplaceAll(day, "-", ""), u.Query().Get("teedate"), "%s should link to its own day", game)
+		}
+	}
+}
+
 func TestScrapeTimes_Online(t *testing.T) {
 	if !*runOnline {
 		t.Skip("online test disabled; run with: go test -args -online")
@@ -185,7 +247,7 @@ func TestScrapeTimes_Online(t *testing.T) {
 			selectedDate := time.Now().AddDate(0, 0, 3)
 
 			// ScrapeDates to get one or more concrete URLs (with correct teedate, etc.)
-			dateResults, err := ScrapeDates(cse.calendarURL, selectedDate)
+			dateResults, err := ScrapeDates(context.Background(), testFetcher(), cse.calendarURL, selectedDate)
 			assert.NoError(t, err, "ScrapeDates should succeed against the live site")
 			require.NotNil(t, dateResults, "dates results map should not be nil")
 
@@ -211,7 +273,7 @@ func TestScrapeTimes_Online(t *testing.T) {
 			assert.NotEmpty(t, q.Get("teedate"), "teedate should be present in query")
 
 			// ScrapeTimes using the searchmatrix URL
-			timeResults, scrapeErr := ScrapeTimes(timesURL)
+			timeResults, scrapeErr := ScrapeTimes(context.Background(), testFetcher(), timesURL)
 
 			// Validate results
 			assert.NoError(t, scrapeErr, "ScrapeTimes should succeed against the live page")
@@ -225,14 +287,18 @@ func TestScrapeTimes_Online(t *testing.T) {
 			}
 
 			// Assertions
-			for layout, slots := range timeResults {
-				require.NotEmpty(t, layout, "layout (game type) should not be empty")
-				require.NotEmpty(t, slots, "each layout should have at least one available slot")
-
-				for _, slot := range slots {
-					require.NotEmpty(t, slot.Time, "timeslot should include a time string")
-					assert.Contains(t, slot.Time, ":", "time should look like HH:MM (contains :)")
-					assert.Greater(t, slot.AvailableSpots, 0, "available spots should be > 0")
+			for game, layouts := range timeResults {
+				require.NotEmpty(t, game, "game type should not be empty")
+
+				for layout, slots := range layouts {
+					require.NotEmpty(t, layout, "layout (course column) should not be empty")
+					require.NotEmpty(t, slots, "each layout should have at least one available slot")
+
+					for _, slot := range slots {
+						require.NotEmpty(t, slot.Time, "timeslot should include a time string")
+						assert.Contains(t, slot.Time, ":", "time should look like HH:MM (contains :)")
+						assert.Greater(t, slot.AvailableSpots, 0, "available spots should be > 0")
+					}
 				}
 			}
 		})
@@ -284,31 +350,134 @@ func TestScrapeTimes_Offline(t *testing.T) {
 			base.Path = "/teetimes/searchmatrix"
 
 			// Run ScrapeTimes against the served snapshot
-			results, scrapeErr := ScrapeTimes(base.String())
+			results, scrapeErr := ScrapeTimes(context.Background(), testFetcher(), base.String())
 
 			// Validate Results
 			assert.NoError(t, scrapeErr, "ScrapeTimes should succeed against the served snapshot")
 			require.NotNil(t, results, "results map should not be nil")
-			require.NotZero(t, len(results), "expected at least one layout (game type) with available times")
+			require.NotZero(t, len(results), "expected at least one game type with available times")
 
 			// Assertions
-			for layout, slots := range results {
-				require.NotEmpty(t, layout, "layout (game type) should not be empty")
-				require.NotEmpty(t, slots, "each layout should have at least one available slot")
-
-				for _, slot := range slots {
-					// Time text formatting and non-empty
-					require.NotEmpty(t, slot.Time, "timeslot should include a time string")
-					assert.Contains(t, slot.Time, ":", "time should look like HH:MM (contains :)")
-
-					// Only available slots should be included by ScrapeTimes
-					assert.Greater(t, slot.AvailableSpots, 0, "available spots should be > 0")
+			for game, layouts := range results {
+				require.NotEmpty(t, game, "game type should not be empty")
+				require.NotEmpty(t, layouts, "each game should have at least one layout")
+
+				for layout, slots := range layouts {
+					require.NotEmpty(t, layout, "layout (course column) should not be empty")
+					require.NotEmpty(t, slots, "each layout should have at least one available slot")
+
+					for _, slot := range slots {
+						// Time text formatting and non-empty
+						require.NotEmpty(t, slot.Time, "timeslot should include a time string")
+						assert.Contains(t, slot.Time, ":", "time should look like HH:MM (contains :)")
+
+						// Only available slots should be included by ScrapeTimes
+						assert.Greater(t, slot.AvailableSpots, 0, "available spots should be > 0")
+					}
 				}
 			}
 		})
 	}
 }
 
+func TestScrapeTimes_Fees(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile(filepath.Join("testdata", "the_springs.html"))
+	require.NoError(t, err, "failed to read local html file")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(html)
+	}))
+	defer srv.Close()
+
+	results, err := ScrapeTimes(context.Background(), testFetcher(), srv.URL+"/teetimes/searchmatrix")
+	require.NoError(t, err)
+
+	// The Springs lists one price per column, labelled with the column header
+	for header, layouts := range results {
+		for _, slots := range layouts {
+			for _, slot := range slots {
+				require.Len(t, slot.Fees, 1, "each Quick18 cell has a single price")
+				fee := slot.Fees[0]
+				assert.Equal(t, "$", fee.Currency)
+				assert.Greater(t, fee.Amount, 0.0)
+				assert.Equal(t, header, normaliseGameName(fee.Label), "fee label should be the raw column header")
+			}
+		}
+	}
+
+	require.NotEmpty(t, results["18 Holes"]["18 Holes (Double Loop)"])
+	first := results["18 Holes"]["18 Holes (Double Loop)"][0]
+	assert.Equal(t, "5:37AM", first.Time)
+	assert.Equal(t, shared.Fee{Amount: 35, Currency: "$", Label: "18 Holes"}, first.Fees[0])
+
+	for _, slots := range results["9 Holes Concession"] {
+		for _, slot := range slots {
+			assert.True(t, slot.Fees[0].Concession, "concession column should be flagged")
+		}
+	}
+}
+
+func TestScrapeTimes_BookingURL(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile(filepath.Join("testdata", "the_springs.html"))
+	require.NoError(t, err, "failed to read local html file")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(html)
+	}))
+	defer srv.Close()
+
+	results, err := ScrapeTimes(context.Background(), testFetcher(), srv.URL+"/teetimes/searchmatrix")
+	require.NoError(t, err)
+
+	for _, layouts := range results {
+		for _, slots := range layouts {
+			for _, slot := range slots {
+				assert.Contains(t, slot.BookingURL, "/teetime/", "each slot should link to its own tee time")
+			}
+		}
+	}
+
+	// The 5:37 AM 18 Holes and 18 Holes Concession cells have different psids
+	const layout = "18 Holes (Double Loop)"
+	require.NotEmpty(t, results["18 Holes"][layout])
+	require.NotEmpty(t, results["18 Holes Concession"][layout])
+	assert.Equal(t, "https://springs.quick18.com/teetimes/course/1134/teetime/202510160537?psid=6378&p=0", results["18 Holes"][layout][0].BookingURL)
+	assert.Equal(t, "https://springs.quick18.com/teetimes/course/1134/teetime/202510160537?psid=6380&p=0", results["18 Holes Concession"][layout][0].BookingURL)
+}
+
+func TestScrapeTimes_Layouts(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile(filepath.Join("testdata", "hamersley.html"))
+	require.NoError(t, err, "failed to read local html file")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(html)
+	}))
+	defer srv.Close()
+
+	results, err := ScrapeTimes(context.Background(), testFetcher(), srv.URL+"/teetimes/searchmatrix")
+	require.NoError(t, err)
+
+	// Hamersley splits its 9 hole times across the front and back nines
+	allowed := []string{"Hamersley", "Back 9 Morning", "Back 9 Afternoon"}
+	seen := map[string]bool{}
+	for _, layouts := range results {
+		for layout := range layouts {
+			assert.Contains(t, allowed, layout, "layout should come from the Course column")
+			seen[layout] = true
+		}
+	}
+	assert.True(t, seen["Hamersley"], "expected times on the main course layout")
+}
+
 func TestParseTimeCell(t *testing.T) {
 	t.Parallel()
 
@@ -460,3 +629,167 @@ func TestNormaliseGameName(t *testing.T) {
 		})
 	}
 }
+
+func TestProviderDetect(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{"Quick18 hosted domain", "https://springs.quick18.com/teetimes/searchmatrix", true},
+		{"Searchmatrix on another host", "http://127.0.0.1:8080/teetimes/searchmatrix?teedate=20250211", true},
+		{"Searchmatrix under a path prefix", "http://127.0.0.1:8080/springs/teetimes/searchmatrix", true},
+		{"MiClub URL", "https://fremantlepublic.miclub.com.au/guests/bookings/ViewPublicCalendar.msp", false},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := url.Parse(c.url)
+			require.NoError(t, err)
+			assert.Equal(t, c.want, Provider{}.Detect(u))
+		})
+	}
+}
+
+func TestProviderNormaliseURL(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"Already clean", "https://springs.quick18.com/teetimes/searchmatrix", "https://springs.quick18.com/teetimes/searchmatrix"},
+		{"Date from the browser", "https://Springs.Quick18.com/teetimes/searchmatrix?teedate=20250211#results", "https://springs.quick18.com/teetimes/searchmatrix"},
+		{"Booking page", "https://hamersley.quick18.com/teetimes/teetime/202502110607?rate=1", "https://hamersley.quick18.com/teetimes/searchmatrix"},
+		{"Bare site", "https://hamersley.quick18.com/", "https://hamersley.quick18.com/teetimes/searchmatrix"},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := url.Parse(c.url)
+			require.NoError(t, err)
+			assert.Equal(t, c.want, Provider{}.NormaliseURL(u))
+		})
+	}
+}
+
+func TestProviderScrapeTimesFiltersGame(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile(filepath.Join("testdata", "the_springs.html"))
+	require.NoError(t, err, "failed to read local html file")
+
+	srv := servePage(t, string(html))
+
+	results, err := Provider{}.ScrapeTimes(context.Background(), testFetcher(), srv.URL+"/teetimes/searchmatrix", "18 Holes")
+	require.NoError(t, err)
+	require.NotEmpty(t, results, "the requested game column should have layouts")
+	assert.NotEmpty(t, results["18 Holes (Double Loop)"])
+	for layout := range results {
+		assert.Contains(t, []string{"18 Holes (Double Loop)", "9 Holes"}, layout)
+	}
+}
+
+func TestScrapeTimesReusesDatePage(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile(filepath.Join("testdata", "the_springs.html"))
+	require.NoError(t, err, "failed to read local html file")
+
+	var mu sync.Mutex
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(html)
+	}))
+	defer srv.Close()
+
+	day, err := time.Parse("2006-01-02", "2025-10-16")
+	require.NoError(t, err)
+
+	f := testFetcher()
+	games, err := ScrapeDates(context.Background(), f, srv.URL+"/teetimes/searchmatrix", day)
+	require.NoError(t, err)
+	require.NotEmpty(t, games)
+
+	// Every game on the day points at the same page, which was already fetched
+	for game, gameURL := range games {
+		results, err := Provider{}.ScrapeTimes(context.Background(), f, gameURL, game)
+		require.NoError(t, err)
+		assert.NotEmpty(t, results, "expected times for %s", game)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, hits, "the searchmatrix page should only be downloaded once")
+}
+
+func TestScrapeUnrecognisedPage(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.URL.Query().Get("teedate") == "20251017" {
+			_, _ = w.Write([]byte("<html><body><p>There are no tee times available for this date.</p></body></html>"))
+			return
+		}
+		_, _ = w.Write([]byte("<html><body><h1>Under maintenance</h1></body></html>"))
+	}))
+	defer srv.Close()
+
+	// A day with no tee times at all is just empty
+	day, err := time.Parse("2006-01-02", "2025-10-17")
+	require.NoError(t, err)
+	results, err := ScrapeDateRange(context.Background(), testFetcher(), srv.URL+"/teetimes/searchmatrix", day, day)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// Anything else without the matrix table is a page we don't understand
+	var pageErr *fetch.PageError
+	_, err = ScrapeDateRange(context.Background(), testFetcher(), srv.URL+"/teetimes/searchmatrix", day.AddDate(0, 0, -1), day)
+	require.ErrorAs(t, err, &pageErr, "one bad day should fail the range")
+
+	_, err = ScrapeTimes(context.Background(), testFetcher(), srv.URL+"/teetimes/searchmatrix?teedate=20251016")
+	require.ErrorAs(t, err, &pageErr)
+	assert.Contains(t, pageErr.Missing, "table.matrixTable")
+}
+
+func TestLayoutChanged(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile(filepath.Join("testdata", "the_springs.html"))
+	require.NoError(t, err, "failed to read local html file")
+
+	day, err := time.Parse("2006-01-02", "2025-10-16")
+	require.NoError(t, err)
+
+	t.Run("Renamed player column", func(t *testing.T) {
+		srv := servePage(t, strings.ReplaceAll(string(html), "matrixPlayers", "mtrxPlayers"))
+
+		games, err := ScrapeDates(context.Background(), testFetcher(), srv.URL+"/teetimes/searchmatrix", day)
+		var pageErr *fetch.PageError
+		require.ErrorAs(t, err, &pageErr)
+		assert.Equal(t, []string{"table.matrixTable td.matrixPlayers"}, pageErr.Missing)
+		assert.Nil(t, games, "there should be no made up games when the page can't be read")
+	})
+
+	t.Run("Fully booked is not a layout change", func(t *testing.T) {
+		srv := servePage(t, strings.ReplaceAll(string(html), "teebutton", "teebutton-gone"))
+
+		games, err := ScrapeDates(context.Background(), testFetcher(), srv.URL+"/teetimes/searchmatrix", day)
+		require.NoError(t, err)
+		assert.Empty(t, games, "no games means no games, not a fake \"All Tee Times\" entry")
+	})
+}
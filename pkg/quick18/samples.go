@@ -0,0 +1,14 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package quick18
+
+import "embed"
+
+// SamplePages holds the saved Quick18 pages the tests run against. They're
+// built in so --demo can show the tool working without a real course.
+//
+//go:embed testdata/*.html
+var SamplePages embed.FS
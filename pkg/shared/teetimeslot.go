@@ -5,7 +5,58 @@
 
 package shared
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type TeeTimeSlot struct {
 	Time           string
 	AvailableSpots int
+	Fees           []Fee
+	BookingURL     string // link to book this exact tee time, if the site has one
+}
+
+// Fee is one green fee option listed against a tee time
+type Fee struct {
+	Amount     float64 `json:"amount"`
+	Currency   string  `json:"currency"` // symbol shown on the booking site, e.g. "$"
+	Label      string  `json:"label"`    // e.g. "Weekday 9H Peak - Concession"
+	Concession bool    `json:"concession"`
+}
+
+// ParseFee builds a Fee from a price such as "$30.00" and its label.
+// It returns false if the price doesn't contain a number.
+func ParseFee(price, label string) (Fee, bool) {
+	price = strings.TrimSpace(price)
+	label = strings.Join(strings.Fields(label), " ")
+
+	// Everything before the first digit is treated as the currency symbol
+	idx := strings.IndexAny(price, "0123456789")
+	if idx == -1 {
+		return Fee{}, false
+	}
+	currency := strings.TrimSpace(price[:idx])
+
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(price[idx:], ",", ""), 64)
+	if err != nil {
+		return Fee{}, false
+	}
+
+	return Fee{
+		Amount:     amount,
+		Currency:   currency,
+		Label:      label,
+		Concession: strings.Contains(strings.ToLower(label), "concession"),
+	}, true
+}
+
+// String renders the fee like "$23.50 Weekday 9H Peak - Concession"
+func (f Fee) String() string {
+	s := fmt.Sprintf("%s%.2f", f.Currency, f.Amount)
+	if f.Label != "" {
+		s += " " + f.Label
+	}
+	return s
 }
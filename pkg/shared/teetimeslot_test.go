@@ -0,0 +1,72 @@
+// Copyright (c) 2024 Adam Wyatt
+//
+// This software is licensed under the MIT License.
+// See the LICENSE file in the root of the repository for details.
+
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFee(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		price  string
+		label  string
+		want   Fee
+		wantOK bool
+	}{
+		{
+			name:   "MiClub price with padding",
+			price:  "\n      $30.00\n   ",
+			label:  "  Weekday 9H Peak \n",
+			want:   Fee{Amount: 30, Currency: "$", Label: "Weekday 9H Peak"},
+			wantOK: true,
+		},
+		{
+			name:   "Concession label sets flag",
+			price:  "$23.50",
+			label:  "Weekday 9H Peak - Concession",
+			want:   Fee{Amount: 23.5, Currency: "$", Label: "Weekday 9H Peak - Concession", Concession: true},
+			wantOK: true,
+		},
+		{
+			name:   "Thousands separator",
+			price:  "$1,200.00",
+			label:  "Annual Pass",
+			want:   Fee{Amount: 1200, Currency: "$", Label: "Annual Pass"},
+			wantOK: true,
+		},
+		{
+			name:   "Not a price",
+			price:  "N/A",
+			label:  "9 Holes",
+			wantOK: false,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := ParseFee(c.price, c.label)
+			assert.Equal(t, c.wantOK, ok)
+			if c.wantOK {
+				assert.Equal(t, c.want, got)
+			}
+		})
+	}
+}
+
+func TestFeeString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "$23.50 Weekday 9H Peak - Concession",
+		Fee{Amount: 23.5, Currency: "$", Label: "Weekday 9H Peak - Concession"}.String())
+	assert.Equal(t, "$35.00", Fee{Amount: 35, Currency: "$"}.String())
+}